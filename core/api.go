@@ -50,8 +50,11 @@ import (
 	"encoding/json"
 	"errors"
 	_log "log"
+	"net/http"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/chirino/graphql"
 	"github.com/dosco/graphjin/core/internal/allow"
@@ -73,11 +76,21 @@ const (
 
 	// User role if pre-defined
 	UserRoleKey
+
+	// Database transaction (*sql.Tx) to execute the query within
+	TxKey
 )
 
 // GraphJin struct is an instance of the GraphJin engine it holds all the required information like
 // datase schemas, relationships, etc that the GraphQL to SQL compiler would need to do it's job.
+// Use the Reload function to rebuild it when the database schema changes.
 type GraphJin struct {
+	e  atomic.Value // *graphjin
+	mu sync.Mutex
+}
+
+// graphjin struct is the engine built from the database schema and the config
+type graphjin struct {
 	conf        *Config
 	db          *sql.DB
 	log         *_log.Logger
@@ -86,10 +99,12 @@ type GraphJin struct {
 	allowList   *allow.List
 	encKey      [32]byte
 	queries     map[string]*cquery
+	hashes      map[string]string
 	roles       map[string]*Role
 	roleStmt    string
 	roleStmtMD  psql.Metadata
 	rmap        map[string]resItem
+	rfmap       map[string]rootField
 	abacEnabled bool
 	qc          *qcode.Compiler
 	pc          *psql.Compiler
@@ -109,13 +124,39 @@ func newGraphJin(conf *Config, db *sql.DB, dbinfo *sdata.DBInfo) (*GraphJin, err
 		conf = &Config{Debug: true, DisableAllowList: true}
 	}
 
-	gj := &GraphJin{
+	gj, err := newEngine(conf, db, dbinfo, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &GraphJin{}
+	g.e.Store(gj)
+
+	return g, nil
+}
+
+// newEngine builds the engine, when reloading prev is the current engine
+// and its encryption key is reused so that existing cursors remain valid,
+// its allow list is reused as well
+func newEngine(conf *Config, db *sql.DB, dbinfo *sdata.DBInfo, prev *graphjin) (*graphjin, error) {
+	gj := &graphjin{
 		conf:   conf,
 		db:     db,
 		dbinfo: dbinfo,
 		log:    _log.New(os.Stdout, "", 0),
 	}
 
+	if prev != nil {
+		gj.allowList = prev.allowList
+	}
+
+	// inflections are global so they are only added once
+	if prev == nil && conf.EnableInflection {
+		if err := initInflection(conf); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := gj.initConfig(); err != nil {
 		return nil, err
 	}
@@ -128,6 +169,10 @@ func newGraphJin(conf *Config, db *sql.DB, dbinfo *sdata.DBInfo) (*GraphJin, err
 		return nil, err
 	}
 
+	if err := gj.initRootFields(); err != nil {
+		return nil, err
+	}
+
 	if err := gj.initSchema(); err != nil {
 		return nil, err
 	}
@@ -148,11 +193,16 @@ func newGraphJin(conf *Config, db *sql.DB, dbinfo *sdata.DBInfo) (*GraphJin, err
 		return nil, err
 	}
 
-	if conf.SecretKey != "" {
+	switch {
+	case prev != nil:
+		gj.encKey = prev.encKey
+
+	case conf.SecretKey != "":
 		sk := sha256.Sum256([]byte(conf.SecretKey))
 		conf.SecretKey = ""
 		gj.encKey = sk
-	} else {
+
+	default:
 		gj.encKey = crypto.NewEncryptionKey()
 	}
 
@@ -167,14 +217,22 @@ type Result struct {
 	sql  string
 	role string
 
-	Error      string          `json:"message,omitempty"`
+	Errors     []Error         `json:"errors,omitempty"`
 	Data       json.RawMessage `json:"data,omitempty"`
-	Extensions *extensions     `json:"extensions,omitempty"`
+	Extensions *Extensions     `json:"extensions,omitempty"`
 }
 
 // ReqConfig is used to pass request specific config values to the GraphQLEx and SubscribeEx functions. Dynamic variables can be set here.
 type ReqConfig struct {
 	Vars map[string]interface{}
+
+	// EnableTracing when set to true returns the Apollo tracing
+	// extension with the result of this request
+	EnableTracing bool
+
+	// Header contains the headers of the incoming request, remote
+	// resolvers forward the ones listed in their pass_headers config
+	Header http.Header
 }
 
 // GraphQL function is called on the GraphJin struct to convert the provided GraphQL query into an
@@ -183,13 +241,34 @@ type ReqConfig struct {
 //
 // In developer mode all names queries are saved into a file `allow.list` and in production mode only
 // queries from this file can be run.
-func (gj *GraphJin) GraphQL(
+func (g *GraphJin) GraphQL(
 	c context.Context,
 	query string,
 	vars json.RawMessage,
 	rc *ReqConfig) (*Result, error) {
+	return g.GraphQLEx(c, query, "", vars, rc)
+}
 
-	op, name := qcode.GetQType(query)
+// GraphQLEx is the extended version of the GraphQL function, it takes the name of the
+// operation to execute when the query contains more than one operation. If opName is empty
+// the first operation in the query is executed.
+func (g *GraphJin) GraphQLEx(
+	c context.Context,
+	query string,
+	opName string,
+	vars json.RawMessage,
+	rc *ReqConfig) (*Result, error) {
+	return g.engine().graphQL(c, query, opName, vars, rc)
+}
+
+func (gj *graphjin) graphQL(
+	c context.Context,
+	query string,
+	opName string,
+	vars json.RawMessage,
+	rc *ReqConfig) (*Result, error) {
+
+	op, name := qcode.GetQType(query, opName)
 
 	ct := scontext{
 		Context: c,
@@ -199,29 +278,35 @@ func (gj *GraphJin) GraphQL(
 		name:    name,
 	}
 
+	if gj.tracingEnabled(rc) {
+		ct.tr = newTrace()
+	}
+
 	res := &Result{
 		op:   ct.op,
 		name: ct.name,
 	}
 
 	if ct.op == qcode.QTSubscription {
-		return res, errors.New("use 'core.Subscribe' for subscriptions")
+		return res, res.addError(wrapErr(ErrValidation,
+			errors.New("use 'core.Subscribe' for subscriptions")))
 	}
 
 	if ct.op == qcode.QTMutation && gj.schema.DBType() == "mysql" {
-		return res, errors.New("mysql: mutations not supported")
+		return res, res.addError(wrapErr(ErrValidation,
+			errors.New("mysql: mutations not supported")))
 	}
 
 	// use the chirino/graphql library for introspection queries
 	// disabled when allow list is enforced
 	if !gj.conf.EnforceAllowList && ct.name == "IntrospectionQuery" {
-		r := gj.ge.ServeGraphQL(&graphql.Request{Query: query})
+		r := gj.ge.ServeGraphQL(&graphql.Request{Query: query, OperationName: opName})
 		res.Data = r.Data
 
-		if r.Error() != nil {
-			res.Error = r.Error().Error()
+		if err := r.Error(); err != nil {
+			return res, res.addError(wrapErr(ErrValidation, err))
 		}
-		return res, r.Error()
+		return res, nil
 	}
 
 	var role string
@@ -235,8 +320,9 @@ func (gj *GraphJin) GraphQL(
 	qr, err := ct.execQuery(query, vars, role)
 
 	if err != nil {
-		res.Error = err.Error()
+		res.Errors = []Error{newError(err)}
 	}
+	res.Errors = append(res.Errors, ct.errs...)
 
 	if qr.q != nil {
 		res.sql = qr.q.st.sql
@@ -245,12 +331,96 @@ func (gj *GraphJin) GraphQL(
 	res.Data = json.RawMessage(qr.data)
 	res.role = qr.role
 
+	if ct.tr != nil {
+		ct.tr.end()
+		res.Extensions = &Extensions{Tracing: ct.tr}
+	}
+
+	if h := gj.conf.Hooks; h != nil {
+		if err1 := h.AfterExecute(c, res); err1 != nil {
+			res.Data = nil
+			res.Errors = append(res.Errors, newError(err1))
+
+			if err == nil {
+				err = err1
+			}
+		}
+	}
+
 	return res, err
 }
 
+// GraphQLTx function is like the GraphQL function but executes the query within the provided
+// database transaction. The user id session setup and role query are executed on the same
+// transaction. Committing or rolling back the transaction is left to the caller. The query
+// timeout is not set as a statement timeout on the transaction so it doesn't change the
+// timeout of the caller's other statements, only the context of the query is bound by it.
+func (g *GraphJin) GraphQLTx(
+	c context.Context,
+	tx *sql.Tx,
+	query string,
+	vars json.RawMessage,
+	rc *ReqConfig) (*Result, error) {
+	return g.GraphQLEx(context.WithValue(c, TxKey, tx), query, "", vars, rc)
+}
+
+// BatchReq is a single operation in a batch of GraphQL requests
+type BatchReq struct {
+	Query  string
+	OpName string
+	Vars   json.RawMessage
+}
+
+// GraphQLBatch function executes a batch of GraphQL operations and returns their results
+// in the same order. Queries in the batch are executed concurrently while mutations are
+// executed one at a time once all the operations before them have completed.
+func (g *GraphJin) GraphQLBatch(
+	c context.Context,
+	reqs []BatchReq,
+	rc *ReqConfig) []*Result {
+
+	// all the operations in a batch use the same engine
+	gj := g.engine()
+
+	var wg sync.WaitGroup
+	res := make([]*Result, len(reqs))
+
+	for i := range reqs {
+		r := reqs[i]
+
+		if op, _ := qcode.GetQType(r.Query, r.OpName); op == qcode.QTMutation {
+			wg.Wait()
+			res[i], _ = gj.graphQL(c, r.Query, r.OpName, r.Vars, rc)
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res[i], _ = gj.graphQL(c, r.Query, r.OpName, r.Vars, rc)
+		}(i)
+	}
+	wg.Wait()
+
+	return res
+}
+
 // Operation function return the operation type and name from the query.
 // It uses a very fast algorithm to extract the operation without having to parse the query.
 func Operation(query string) (OpType, string) {
-	qt, name := qcode.GetQType(query)
+	return OperationEx(query, "")
+}
+
+// OperationEx is the extended version of the Operation function, it returns the operation
+// type of the operation named opName in the query.
+func OperationEx(query, opName string) (OpType, string) {
+	qt, name := qcode.GetQType(query, opName)
 	return OpType(qt), name
 }
+
+// PersistedQuery function returns the query from the allow list that matches the
+// sha256 hash (hex encoded). It's used to support automatic persisted queries.
+func (g *GraphJin) PersistedQuery(hash string) (string, bool) {
+	query, ok := g.engine().hashes[strings.ToLower(hash)]
+	return query, ok
+}
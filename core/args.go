@@ -18,7 +18,7 @@ type args struct {
 	cindx  int // index of cursor arg
 }
 
-func (gj *GraphJin) argList(c context.Context, md psql.Metadata, vars []byte, rc *ReqConfig) (
+func (gj *graphjin) argList(c context.Context, md psql.Metadata, vars []byte, rc *ReqConfig) (
 	args, error) {
 
 	ar := args{cindx: -1}
@@ -123,7 +123,7 @@ func parseVarVal(v json.RawMessage) interface{} {
 	}
 }
 
-func (gj *GraphJin) roleQueryArgList(c context.Context) (args, error) {
+func (gj *graphjin) roleQueryArgList(c context.Context) (args, error) {
 	ar := args{cindx: -1}
 	params := gj.roleStmtMD.Params()
 	vl := make([]interface{}, len(params))
@@ -18,7 +18,7 @@ type stmt struct {
 	sql  string
 }
 
-func (gj *GraphJin) compileQuery(cq *cquery, role string) error {
+func (gj *graphjin) compileQuery(cq *cquery, role string) error {
 	var err error
 
 	// In production mode enforce the allow list and
@@ -40,10 +40,10 @@ func (gj *GraphJin) compileQuery(cq *cquery, role string) error {
 		err = gj.compileQueryFn(cq, role)
 	}
 
-	return err
+	return compileErr(err)
 }
 
-func (gj *GraphJin) compileQueryFn(cq *cquery, role string) error {
+func (gj *graphjin) compileQueryFn(cq *cquery, role string) error {
 	var err error
 
 	switch cq.q.op {
@@ -55,7 +55,7 @@ func (gj *GraphJin) compileQueryFn(cq *cquery, role string) error {
 		}
 
 	case qcode.QTMutation:
-		err = gj.buildRoleStmt(cq, role)
+		err = gj.buildMutationStmts(cq, role)
 
 	default:
 		err = errors.New("unknown query")
@@ -65,7 +65,7 @@ func (gj *GraphJin) compileQueryFn(cq *cquery, role string) error {
 	return err
 }
 
-func (gj *GraphJin) buildRoleStmt(cq *cquery, role string) error {
+func (gj *graphjin) buildRoleStmt(cq *cquery, role string) error {
 	query := cq.q.query
 	vars := cq.q.vars
 
@@ -83,11 +83,19 @@ func (gj *GraphJin) buildRoleStmt(cq *cquery, role string) error {
 		}
 	}
 
-	qc, err := gj.qc.Compile(query, vm, ro.Name)
+	qc, err := gj.qc.CompileOp(query, cq.q.name, vm, ro.Name)
 	if err != nil {
 		return err
 	}
 
+	cq.st.role = ro
+	cq.st.qc = qc
+
+	// nothing to fetch from the database
+	if len(qc.Selects) == 0 {
+		return nil
+	}
+
 	var w bytes.Buffer
 
 	cq.st.md, err = gj.pc.Compile(&w, qc)
@@ -95,14 +103,70 @@ func (gj *GraphJin) buildRoleStmt(cq *cquery, role string) error {
 		return err
 	}
 
-	cq.st.role = ro
-	cq.st.qc = qc
 	cq.st.sql = w.String()
 
 	return nil
 }
 
-func (gj *GraphJin) buildMultiStmt(cq *cquery) error {
+// buildMutationStmts compiles each root field of the mutation into its own
+// statement, these are executed one after the other within a transaction.
+func (gj *graphjin) buildMutationStmts(cq *cquery, role string) error {
+	query := cq.q.query
+	vars := cq.q.vars
+
+	ro, ok := gj.roles[role]
+	if !ok {
+		return fmt.Errorf(`roles '%s' not defined in c.gj.config`, role)
+	}
+
+	var vm map[string]json.RawMessage
+	var err error
+
+	if len(vars) != 0 {
+		if err := json.Unmarshal(vars, &vm); err != nil {
+			return fmt.Errorf("variables: %w", err)
+		}
+	}
+
+	qcs, err := gj.qc.CompileRoots(query, cq.q.name, vm, ro.Name)
+	if err != nil {
+		return err
+	}
+
+	// nothing to change in the database
+	if len(qcs[0].Selects) == 0 {
+		cq.st = stmt{role: ro, qc: qcs[0]}
+		return nil
+	}
+
+	stmts := make([]stmt, len(qcs))
+	sqls := make([]string, len(qcs))
+
+	for i, qc := range qcs {
+		var w bytes.Buffer
+
+		s := &stmts[i]
+		if s.md, err = gj.pc.Compile(&w, qc); err != nil {
+			return err
+		}
+
+		s.role = ro
+		s.qc = qc
+		s.sql = w.String()
+		sqls[i] = s.sql
+	}
+
+	cq.st = stmts[0]
+
+	if len(stmts) > 1 {
+		cq.mstmts = stmts
+		cq.st.sql = strings.Join(sqls, ";\n")
+	}
+
+	return nil
+}
+
+func (gj *graphjin) buildMultiStmt(cq *cquery) error {
 	var vm map[string]json.RawMessage
 	var md psql.Metadata
 	var err error
@@ -127,11 +191,18 @@ func (gj *GraphJin) buildMultiStmt(cq *cquery) error {
 			continue
 		}
 
-		qc, err := gj.qc.Compile(query, vm, role.Name)
+		qc, err := gj.qc.CompileOp(query, cq.q.name, vm, role.Name)
 		if err != nil {
 			return err
 		}
 
+		// nothing to fetch from the database
+		if len(qc.Selects) == 0 {
+			cq.stmts = nil
+			cq.st = stmt{role: role, qc: qc}
+			return nil
+		}
+
 		cq.stmts = append(cq.stmts, stmt{role: role, qc: qc})
 		s := &cq.stmts[len(cq.stmts)-1]
 
@@ -151,7 +222,7 @@ func (gj *GraphJin) buildMultiStmt(cq *cquery) error {
 }
 
 //nolint: errcheck
-func (gj *GraphJin) renderUserQuery(md *psql.Metadata, stmts []stmt) (string, error) {
+func (gj *graphjin) renderUserQuery(md *psql.Metadata, stmts []stmt) (string, error) {
 	if gj.conf.RolesQuery == "" {
 		return "", errors.New("roles_query: empty of not defined")
 	}
@@ -1,6 +1,8 @@
 package core
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"path"
@@ -31,6 +33,11 @@ type Config struct {
 	// path is assumed to be the same as the config path (allow.list)
 	AllowListFile string `mapstructure:"allow_list_file"`
 
+	// SchemaSnapshot is the path to a schema snapshot file (see WriteSchemaSnapshot),
+	// when set the database schema is loaded from this file instead of being
+	// discovered by querying the database
+	SchemaSnapshot string `mapstructure:"schema_snapshot"`
+
 	// SetUserID forces the database session variable `user.id` to
 	// be set to the user id. This variables can be used by triggers
 	// or other database functions
@@ -61,6 +68,10 @@ type Config struct {
 	// creating relationships between tables, etc
 	Tables []Table
 
+	// Functions are database functions (Postgres) or stored procedures (MySQL)
+	// that can be called as mutations
+	Functions []Function
+
 	// RolesQuery if set enabled attributed based access control. This query
 	// is used to fetch the user attributes that then dynamically define the users
 	// role.
@@ -85,9 +96,18 @@ type Config struct {
 	// Database type name. Defaults to 'postgres' (options: mysql, postgres)
 	DBType string `mapstructure:"db_type"`
 
+	// TypeMap maps database types to GraphQL scalar types (eg. citext: String).
+	// The built-in scalars are Int, BigInt, Float, Decimal, Boolean, UUID, Date,
+	// DateTime and JSON, other names are added to the schema as custom scalars
+	TypeMap map[string]string `mapstructure:"type_map"`
+
 	// Log warnings and other debug information
 	Debug bool
 
+	// EnableTracing returns the Apollo tracing extension with the result
+	// of every request. It can also be enabled per request using ReqConfig
+	EnableTracing bool `mapstructure:"enable_tracing"`
+
 	// Useful for quickly debugging. Please set to false in production
 	CredsInVars bool `mapstructure:"creds_in_vars"`
 
@@ -101,6 +121,18 @@ type Config struct {
 	// Default to 20
 	DefaultLimit int `mapstructure:"default_limit"`
 
+	// QueryTimeout is the max time a query can take to run on the
+	// database, it can be overridden for each role. Disabled if not set
+	QueryTimeout time.Duration `mapstructure:"query_timeout"`
+
+	// Hooks are used to intercept requests at various stages of their
+	// lifecycle. For example for auditing, metrics or custom authorization
+	Hooks Hooks `mapstructure:"-"`
+
+	// RootFields are top-level query and mutation fields resolved by
+	// Go functions instead of the database
+	RootFields []RootField `mapstructure:"-"`
+
 	rtmap map[string]resFn
 }
 
@@ -112,6 +144,22 @@ type Table struct {
 	Type      string
 	Blocklist []string
 	Columns   []Column
+
+	// Description is used in the GraphQL schema instead of the
+	// comment set on the table in the database
+	Description string
+}
+
+// Function struct defines a database function (Postgres) or stored procedure
+// (MySQL) that's added as a mutation field, the arguments are the parameters of
+// the function. Functions returning rows of a table return them with a nested
+// selection while the rest return their value, procedures return null.
+type Function struct {
+	Name string
+
+	// Roles allowed to call the function, defaults to all roles except
+	// anon. The anon role has to be listed to allow anonymous calls
+	Roles []string
 }
 
 // Column struct defines a database column
@@ -121,6 +169,19 @@ type Column struct {
 	Primary    bool
 	Array      bool
 	ForeignKey string `mapstructure:"related_to"`
+
+	// Description is used in the GraphQL schema instead of the
+	// comment set on the column in the database
+	Description string
+
+	// SQL makes this a computed column, its value is computed using this
+	// SQL expression (eg. users.first_name || ' ' || users.last_name). The
+	// expression is used as is in queries that join other tables so columns
+	// must be qualified with the table name. The table can be used to pass
+	// the row to a function (eg. full_name(users)). Computed columns can be
+	// selected, filtered and sorted but not set by mutations.
+	// Type defaults to text
+	SQL string `mapstructure:"sql"`
 }
 
 // Role struct contains role specific access control values for for all database tables
@@ -129,6 +190,25 @@ type Role struct {
 	Match  string
 	Tables []RoleTable
 	tm     map[string]*RoleTable
+
+	// MaxDepth is the max nesting depth of queries made by this role
+	MaxDepth int `mapstructure:"max_depth"`
+
+	// MaxSelectors is the max number of selectors (tables and relationships)
+	// in a query made by this role. Defaults to 30
+	MaxSelectors int `mapstructure:"max_selectors"`
+
+	// MaxRows is the max total number of rows a query made by this role can
+	// return. It's computed by multiplying the limit of each selector with
+	// the limits of its parents
+	MaxRows int `mapstructure:"max_rows"`
+
+	// MaxCost is the max cost of a query made by this role. Each selector costs
+	// one for every time it's executed (once per row of its parent)
+	MaxCost int `mapstructure:"max_cost"`
+
+	// QueryTimeout overrides the global query timeout for this role
+	QueryTimeout time.Duration `mapstructure:"query_timeout"`
 }
 
 // RoleTable struct contains role specific access control values for a database table
@@ -243,6 +323,15 @@ type Resolver interface {
 	Resolve(ResolverReq) ([]byte, error)
 }
 
+// BatchResolver interface is an optional interface a custom resolver can implement
+// to fetch the data for all the ids of a remote selector in one call. The ids are
+// set in ResolverReq.IDs and a JSON value must be returned for each id in the same
+// order, use null for ids with no data.
+type BatchResolver interface {
+	Resolver
+	ResolveBatch(ResolverReq) ([][]byte, error)
+}
+
 // ResolverProps is a map of properties from the resolver config to be passed
 // to the customer resolver's builder (new) function
 type ResolverProps map[string]interface{}
@@ -259,9 +348,18 @@ type ResolverConfig struct {
 }
 
 type ResolverReq struct {
-	ID  string
+	ID string
+
+	// RawID is the id as a JSON value, strings are quoted
+	RawID json.RawMessage
+
+	IDs []string
 	Sel *qcode.Select
 	Log *log.Logger
+
+	// Context is the context of the request, resolvers should
+	// stop once it's cancelled
+	Context context.Context
 	*ReqConfig
 }
 
@@ -360,6 +458,10 @@ func ReadInConfig(configFile string) (*Config, error) {
 		c.AllowListFile = path.Join(cp, "allow.list")
 	}
 
+	if c.SchemaSnapshot != "" && !filepath.IsAbs(c.SchemaSnapshot) {
+		c.SchemaSnapshot = path.Join(cp, c.SchemaSnapshot)
+	}
+
 	return c, nil
 }
 
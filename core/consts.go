@@ -2,11 +2,11 @@ package core
 
 import (
 	"context"
-	"errors"
+	"fmt"
 )
 
 var (
-	errNotFound = errors.New("not found in prepared statements")
+	errNotFound = fmt.Errorf("%w in prepared statements", ErrNotFound)
 )
 
 func keyExists(ct context.Context, key contextkey) bool {
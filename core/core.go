@@ -1,8 +1,10 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"time"
@@ -21,38 +23,24 @@ const (
 	OpMutation
 )
 
-type extensions struct {
-	Tracing *trace `json:"tracing,omitempty"`
-}
-
-type trace struct {
-	Version   int           `json:"version"`
-	StartTime time.Time     `json:"startTime"`
-	EndTime   time.Time     `json:"endTime"`
-	Duration  time.Duration `json:"duration"`
-	Execution execution     `json:"execution"`
-}
-
-type execution struct {
-	Resolvers []resolver `json:"resolvers"`
-}
-
-type resolver struct {
-	Path        []string      `json:"path"`
-	ParentType  string        `json:"parentType"`
-	FieldName   string        `json:"fieldName"`
-	ReturnType  string        `json:"returnType"`
-	StartOffset int           `json:"startOffset"`
-	Duration    time.Duration `json:"duration"`
-}
-
 type scontext struct {
 	context.Context
 
-	gj   *GraphJin
+	gj   *graphjin
 	op   qcode.QType
 	rc   *ReqConfig
 	name string
+	tr   *Trace
+
+	// partial errors, the query succeeded but
+	// some fields could not be resolved
+	errs []Error
+}
+
+// dbConn is implemented by both *sql.Conn and *sql.Tx
+type dbConn interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
 }
 
 type qres struct {
@@ -61,14 +49,14 @@ type qres struct {
 	role string
 }
 
-func (gj *GraphJin) initDiscover() error {
+func (gj *graphjin) initDiscover() error {
 	if err := gj._initDiscover(); err != nil {
 		return fmt.Errorf("%s: %w", gj.conf.DBType, err)
 	}
 	return nil
 }
 
-func (gj *GraphJin) _initDiscover() error {
+func (gj *graphjin) _initDiscover() error {
 	var err error
 
 	if gj.conf.DBType == "" {
@@ -77,7 +65,13 @@ func (gj *GraphJin) _initDiscover() error {
 
 	// If gj.dbinfo is not null then it's probably set
 	// for tests
-	if gj.dbinfo == nil {
+	if gj.dbinfo != nil {
+		return nil
+	}
+
+	if gj.conf.SchemaSnapshot != "" {
+		gj.dbinfo, err = readSchemaSnapshot(gj.conf)
+	} else {
 		gj.dbinfo, err = sdata.GetDBInfo(
 			gj.db,
 			gj.conf.DBType,
@@ -87,14 +81,14 @@ func (gj *GraphJin) _initDiscover() error {
 	return err
 }
 
-func (gj *GraphJin) initSchema() error {
+func (gj *graphjin) initSchema() error {
 	if err := gj._initSchema(); err != nil {
 		return fmt.Errorf("%s: %w", gj.conf.DBType, err)
 	}
 	return nil
 }
 
-func (gj *GraphJin) _initSchema() error {
+func (gj *graphjin) _initSchema() error {
 	var err error
 
 	if len(gj.dbinfo.Tables) == 0 {
@@ -116,7 +110,7 @@ func (gj *GraphJin) _initSchema() error {
 	return err
 }
 
-func (gj *GraphJin) initCompilers() error {
+func (gj *graphjin) initCompilers() error {
 	var err error
 
 	qcc := qcode.Config{
@@ -124,6 +118,7 @@ func (gj *GraphJin) initCompilers() error {
 		DefaultLimit:     gj.conf.DefaultLimit,
 		EnableInflection: gj.conf.EnableInflection,
 		DBSchema:         gj.schema.DBSchema(),
+		TypeMap:          gj.conf.TypeMap,
 	}
 
 	if gj.allowList != nil && gj.conf.EnforceAllowList {
@@ -139,6 +134,20 @@ func (gj *GraphJin) initCompilers() error {
 		return err
 	}
 
+	for _, f := range gj.conf.Functions {
+		if err := gj.qc.AddProcedure(f.Name, f.Roles); err != nil {
+			return fmt.Errorf("functions: %w", err)
+		}
+	}
+
+	for _, f := range gj.rfmap {
+		if f.Mutation {
+			gj.qc.AddRootField(f.Name, qcode.QTMutation, f.Roles)
+		} else {
+			gj.qc.AddRootField(f.Name, qcode.QTQuery, f.Roles)
+		}
+	}
+
 	gj.pc = psql.NewCompiler(psql.Config{
 		Vars:      gj.conf.Vars,
 		DBType:    gj.schema.DBType(),
@@ -155,13 +164,21 @@ func (c *scontext) execQuery(query string, vars []byte, role string) (qres, erro
 
 	if c.gj.conf.Debug {
 		c.debugLog(&res.q.st)
+
+		for i := 1; i < len(res.q.mstmts); i++ {
+			c.debugLog(&res.q.mstmts[i])
+		}
 	}
 
-	if len(res.data) == 0 || res.q.st.qc.Remotes == 0 {
-		return res, nil
+	// remote joins for mutations with multiple root fields
+	// are resolved for each root field in execMutations
+	if len(res.data) != 0 && len(res.q.mstmts) == 0 && res.q.st.qc.Remotes != 0 {
+		if res, err = c.execRemoteJoin(res); err != nil {
+			return res, err
+		}
 	}
 
-	return c.execRemoteJoin(res)
+	return c.execRootFields(res, vars)
 }
 
 func (c *scontext) resolveSQL(query string, vars []byte, role string) (qres, error) {
@@ -172,15 +189,23 @@ func (c *scontext) resolveSQL(query string, vars []byte, role string) (qres, err
 	res.q = cq
 	res.role = role
 
-	conn, err := c.gj.db.Conn(c)
-	if err != nil {
-		return res, err
+	var conn dbConn
+	var err error
+
+	if tx, ok := c.Value(TxKey).(*sql.Tx); ok && tx != nil {
+		conn = tx
+	} else {
+		c1, err := c.gj.db.Conn(c)
+		if err != nil {
+			return res, wrapErr(ErrDB, err)
+		}
+		defer c1.Close()
+		conn = c1
 	}
-	defer conn.Close()
 
 	if c.gj.conf.SetUserID {
 		if err := c.setLocalUserID(conn); err != nil {
-			return res, err
+			return res, wrapErr(ErrDB, err)
 		}
 	}
 
@@ -191,35 +216,142 @@ func (c *scontext) resolveSQL(query string, vars []byte, role string) (qres, err
 		res.role, err = c.executeRoleQuery(conn)
 	}
 
+	if err != nil {
+		return res, wrapErr(ErrDB, err)
+	}
+
+	// bound the time the query can take using the query
+	// timeout of the role if one is set
+	timeout := c.gj.queryTimeout(res.role)
+
+	if timeout != 0 {
+		pc := c.Context
+		var cancel context.CancelFunc
+
+		c.Context, cancel = context.WithTimeout(pc, timeout)
+		defer func() { cancel(); c.Context = pc }()
+	}
+
+	// a transaction is needed to set a local statement timeout
+	// and to rollback mutations rejected by the hooks
+	txNeeded := (timeout != 0 && c.gj.schema.DBType() != "mysql") ||
+		(c.gj.conf.Hooks != nil && c.op == qcode.QTMutation)
+
+	var otx *sql.Tx
+
+	if c1, ok := conn.(*sql.Conn); ok && txNeeded {
+		if otx, err = c1.BeginTx(c, nil); err != nil {
+			return res, c.dbErr(err)
+		}
+		defer otx.Rollback() //nolint: errcheck
+		conn = otx
+	}
+
+	// the statement timeout is not set on a transaction passed in by the
+	// caller since SET LOCAL would change it for the rest of the transaction,
+	// the query is still bound by the timeout of the context
+	if otx != nil {
+		if err := c.setStatementTimeout(otx, timeout); err != nil {
+			return res, c.dbErr(err)
+		}
+	}
+
+	var st time.Time
+	span := c.startSpan("compile")
+
+	if c.tr != nil {
+		st = time.Now()
+	}
+
+	err = c.gj.compileQuery(cq, res.role)
+	span.End()
+
 	if err != nil {
 		return res, err
 	}
 
-	if err = c.gj.compileQuery(cq, res.role); err != nil {
+	if err := c.onCompile(cq, res.role); err != nil {
 		return res, err
 	}
 
+	if err := c.gj.checkRootFields(cq.st.qc, vars); err != nil {
+		return res, wrapErr(ErrValidation, err)
+	}
+
+	if err := c.gj.validateVars(cq, vars); err != nil {
+		return res, wrapErr(ErrValidation, err)
+	}
+
+	if c.tr != nil {
+		c.tr.Parsing = c.tr.span(st)
+	}
+
+	// the query only has root fields resolved by functions
+	if cq.st.sql == "" {
+		return res, c.saveToAllowList(vars, query)
+	}
+
+	if len(cq.mstmts) != 0 {
+		if res.data, err = c.execMutations(conn, cq.mstmts, vars, res.role); err != nil {
+			return res, err
+		}
+		if err := c.commitTx(otx); err != nil {
+			return res, err
+		}
+		return res, c.saveToAllowList(vars, query)
+	}
+
+	if c.tr != nil {
+		st = time.Now()
+	}
+
 	args, err := c.gj.argList(c, cq.st.md, vars, c.rc)
+	if err != nil {
+		return res, wrapErr(ErrValidation, err)
+	}
+
+	if c.tr != nil {
+		c.tr.Validation = c.tr.span(st)
+	}
+
+	q, values, err := c.beforeExecute(c.withTimeoutHint(cq.st.sql, timeout), args.values)
 	if err != nil {
 		return res, err
 	}
 
-	// var stime time.Time
+	if c.tr != nil {
+		st = time.Now()
+	}
 
-	// if c.gj.conf.EnableTracing {
-	// 	stime = time.Now()
-	// }
+	span = c.startSpan("query")
 
-	row := conn.QueryRowContext(c, cq.st.sql, args.values...)
-	if cq.roleArg {
-		err = row.Scan(&res.role, &res.data)
+	if cq.st.md.Exec() {
+		res.data, err = c.execStmt(conn, cq.st, q, values)
 	} else {
-		err = row.Scan(&res.data)
+		row := conn.QueryRowContext(c, q, values...)
+		if cq.roleArg {
+			err = row.Scan(&res.role, &res.data)
+		} else {
+			err = row.Scan(&res.data)
+		}
+	}
+	span.End()
+
+	if c.tr != nil {
+		c.tr.addDatabase(cq.st.qc, c.tr.span(st))
 	}
 
 	if err == sql.ErrNoRows {
-		return res, err
+		return res, wrapErr(ErrNotFound, err)
 	} else if err != nil {
+		return res, c.dbErr(err)
+	}
+
+	if err := c.onMutation(cq.st.qc, res.data); err != nil {
+		return res, err
+	}
+
+	if err := c.commitTx(otx); err != nil {
 		return res, err
 	}
 
@@ -230,10 +362,8 @@ func (c *scontext) resolveSQL(query string, vars []byte, role string) (qres, err
 
 	res.data = cur.data
 
-	if c.gj.allowList != nil {
-		if err := c.gj.allowList.Set(vars, query); err != nil {
-			return res, err
-		}
+	if err := c.saveToAllowList(vars, query); err != nil {
+		return res, err
 	}
 
 	// if len(stmts) > 1 {
@@ -242,16 +372,159 @@ func (c *scontext) resolveSQL(query string, vars []byte, role string) (qres, err
 	// 	}
 	// }
 
-	// if c.gj.conf.EnableTracing {
-	// 	for _, id := range st.qc.Roots {
-	// 		c.addTrace(st.qc.Selects, id, stime)
-	// 	}
-	// }
-
 	return res, nil
 }
 
-func (c *scontext) executeRoleQuery(conn *sql.Conn) (string, error) {
+// validateVars checks the values of the variables against the columns
+// they are used with, eg. the values of enum or UUID columns
+func (gj *graphjin) validateVars(cq *cquery, vars []byte) error {
+	var vm qcode.Variables
+
+	if len(vars) != 0 {
+		if err := json.Unmarshal(vars, &vm); err != nil {
+			return fmt.Errorf("variables: %w", err)
+		}
+	}
+
+	stmts := cq.mstmts
+	if len(stmts) == 0 {
+		stmts = []stmt{cq.st}
+	}
+
+	for _, st := range stmts {
+		if st.qc == nil {
+			continue
+		}
+		if err := gj.qc.ValidateVars(st.qc, vm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execStmt executes statements that return no rows like the calls of stored
+// procedures on MySQL, the root field is set to null in the result
+func (c *scontext) execStmt(conn dbConn, st stmt, q string, values []interface{}) ([]byte, error) {
+	if _, err := conn.ExecContext(c, q, values...); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+
+	b.WriteByte('{')
+	writeJSONString(&b, st.qc.Selects[0].FieldName)
+	b.WriteString(": null}")
+
+	return b.Bytes(), nil
+}
+
+// execMutations executes the statements of a mutation with multiple root fields
+// one after the other within a single transaction and merges their results. If any
+// of them fails the whole transaction is rolled back.
+func (c *scontext) execMutations(conn dbConn, stmts []stmt, vars []byte, role string) ([]byte, error) {
+	var tx *sql.Tx
+	var err error
+
+	switch v := conn.(type) {
+	case *sql.Tx:
+		// the callers transaction is committed or rolled back by the caller
+		tx = v
+
+	case *sql.Conn:
+		if tx, err = v.BeginTx(c, nil); err != nil {
+			return nil, wrapErr(ErrDB, err)
+		}
+		defer tx.Rollback() //nolint: errcheck
+	}
+
+	data := make([][]byte, len(stmts))
+
+	for i, st := range stmts {
+		args, err := c.gj.argList(c, st.md, vars, c.rc)
+		if err != nil {
+			return nil, wrapErr(ErrValidation, err)
+		}
+
+		var stime time.Time
+		if c.tr != nil {
+			stime = time.Now()
+		}
+
+		q, values, err := c.beforeExecute(st.sql, args.values)
+		if err != nil {
+			return nil, err
+		}
+
+		span := c.startSpan("query")
+		if st.md.Exec() {
+			data[i], err = c.execStmt(tx, st, q, values)
+		} else {
+			err = tx.QueryRowContext(c, q, values...).Scan(&data[i])
+		}
+		span.End()
+
+		if c.tr != nil {
+			c.tr.addDatabase(st.qc, c.tr.span(stime))
+		}
+
+		if err == sql.ErrNoRows {
+			return nil, wrapErr(ErrNotFound, err)
+		} else if err != nil {
+			return nil, c.dbErr(err)
+		}
+
+		if err := c.onMutation(st.qc, data[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if tx != conn {
+		if err := tx.Commit(); err != nil {
+			return nil, wrapErr(ErrDB, err)
+		}
+	}
+
+	var w bytes.Buffer
+	w.WriteByte('{')
+
+	for i, st := range stmts {
+		cur, err := c.gj.encryptCursor(st.qc, data[i])
+		if err != nil {
+			return nil, err
+		}
+		res := qres{q: &cquery{st: st}, data: cur.data, role: role}
+
+		if st.qc.Remotes != 0 {
+			if res, err = c.execRemoteJoin(res); err != nil {
+				return nil, err
+			}
+		}
+
+		// the result of each statement is an object keyed
+		// by the name of the root field so drop the braces
+		d := bytes.TrimSpace(res.data)
+		if len(d) <= 2 {
+			continue
+		}
+
+		if w.Len() != 1 {
+			w.WriteString(", ")
+		}
+		w.Write(d[1 : len(d)-1])
+	}
+
+	w.WriteByte('}')
+	return w.Bytes(), nil
+}
+
+func (c *scontext) saveToAllowList(vars []byte, query string) error {
+	if c.gj.allowList == nil {
+		return nil
+	}
+	return c.gj.allowList.Set(vars, query, c.name)
+}
+
+func (c *scontext) executeRoleQuery(conn dbConn) (string, error) {
 	var role string
 	var ar args
 	var err error
@@ -268,24 +541,37 @@ func (c *scontext) executeRoleQuery(conn *sql.Conn) (string, error) {
 	return role, err
 }
 
-func (c *scontext) setLocalUserID(conn *sql.Conn) error {
+func (c *scontext) setLocalUserID(conn dbConn) error {
 	var err error
 
+	// within a transaction the setting is scoped to the transaction
+	// so it does not leak into the callers connection
+	scope := "SESSION"
+	if _, ok := conn.(*sql.Tx); ok {
+		scope = "LOCAL"
+	}
+
 	if v := c.Value(UserIDKey); v == nil {
 		return nil
 	} else {
 		switch v1 := v.(type) {
 		case string:
-			_, err = conn.ExecContext(c, `SET SESSION "user.id" = '`+v1+`'`)
+			_, err = conn.ExecContext(c, `SET `+scope+` "user.id" = '`+v1+`'`)
 
 		case int:
-			_, err = conn.ExecContext(c, `SET SESSION "user.id" = `+strconv.Itoa(v1))
+			_, err = conn.ExecContext(c, `SET `+scope+` "user.id" = `+strconv.Itoa(v1))
 		}
 	}
 
 	return err
 }
 
+// addError adds err to the errors list of the result and returns it
+func (r *Result) addError(err error) error {
+	r.Errors = append(r.Errors, newError(err))
+	return err
+}
+
 func (r *Result) Operation() OpType {
 	switch r.op {
 	case qcode.QTQuery:
@@ -315,49 +601,6 @@ func (r *Result) SQL() string {
 	return r.sql
 }
 
-// func (c *scontext) addTrace(sel []qcode.Select, id int32, st time.Time) {
-// 	et := time.Now()
-// 	du := et.Sub(st)
-
-// 	if c.res.Extensions == nil {
-// 		c.res.Extensions = &extensions{&trace{
-// 			Version:   1,
-// 			StartTime: st,
-// 			Execution: execution{},
-// 		}}
-// 	}
-
-// 	c.res.Extensions.Tracing.EndTime = et
-// 	c.res.Extensions.Tracing.Duration = du
-
-// 	n := 1
-// 	for i := id; i != -1; i = sel[i].ParentID {
-// 		n++
-// 	}
-// 	path := make([]string, n)
-
-// 	n--
-// 	for i := id; ; i = sel[i].ParentID {
-// 		path[n] = sel[i].Name
-// 		if sel[i].ParentID == -1 {
-// 			break
-// 		}
-// 		n--
-// 	}
-
-// 	tr := resolver{
-// 		Path:        path,
-// 		ParentType:  "Query",
-// 		FieldName:   sel[id].Name,
-// 		ReturnType:  "object",
-// 		StartOffset: 1,
-// 		Duration:    du,
-// 	}
-
-// 	c.res.Extensions.Tracing.Execution.Resolvers =
-// 		append(c.res.Extensions.Tracing.Execution.Resolvers, tr)
-// }
-
 func (c *scontext) debugLog(st *stmt) {
 	for _, sel := range st.qc.Selects {
 		if sel.SkipRender == qcode.SkipTypeUserNeeded {
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/dosco/graphjin/core/internal/graph"
+	"github.com/dosco/graphjin/core/internal/qcode"
+)
+
+// Error codes set in the extensions of each error in the GraphQL response
+const (
+	ErrCodeNotFound     = "NOT_FOUND"
+	ErrCodeUnauthorized = "UNAUTHORIZED"
+	ErrCodeValidation   = "GRAPHQL_VALIDATION_FAILED"
+	ErrCodeDB           = "DATABASE_ERROR"
+	ErrCodeTimeout      = "QUERY_TIMEOUT"
+	ErrCodeRemote       = "REMOTE_API_ERROR"
+	ErrCodeInternal     = "INTERNAL_SERVER_ERROR"
+)
+
+// Errors returned by the GraphQL function can be matched against
+// these using errors.Is
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrValidation   = errors.New("validation failed")
+	ErrDB           = errors.New("database error")
+	ErrTimeout      = errors.New("query timeout")
+	ErrRemote       = errors.New("remote api error")
+)
+
+// Error is a single entry in the errors list of the GraphQL response
+type Error struct {
+	Message    string          `json:"message"`
+	Locations  []ErrorLocation `json:"locations,omitempty"`
+	Path       []string        `json:"path,omitempty"`
+	Extensions ErrorExtensions `json:"extensions"`
+}
+
+// ErrorLocation is the position in the query where the error was found
+type ErrorLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// ErrorExtensions holds additional information about an error
+type ErrorExtensions struct {
+	Code string `json:"code"`
+}
+
+// kindError tags an error with one of the exported sentinel errors
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e kindError) Unwrap() error {
+	return e.err
+}
+
+func (e kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+func wrapErr(kind, err error) error {
+	if err == nil || errors.Is(err, kind) {
+		return err
+	}
+	return kindError{kind: kind, err: err}
+}
+
+// compileErr tags errors from compiling the query as validation errors
+// unless the role was blocked from accessing part of the query.
+func compileErr(err error) error {
+	if errors.Is(err, qcode.ErrBlocked) {
+		return wrapErr(ErrUnauthorized, err)
+	}
+	return wrapErr(ErrValidation, err)
+}
+
+func newError(err error) Error {
+	var qe *qcode.Error
+	var ge *graph.Error
+
+	e := Error{Message: err.Error()}
+
+	switch {
+	case errors.As(err, &qe):
+		e.Path = qe.Path
+		e.Locations = []ErrorLocation{{Line: qe.Loc.Line, Column: qe.Loc.Column}}
+
+	case errors.As(err, &ge):
+		e.Locations = []ErrorLocation{{Line: ge.Loc.Line, Column: ge.Loc.Column}}
+	}
+
+	e.Extensions.Code = ErrorCode(err)
+	return e
+}
+
+// ErrorCode returns the code set in the extensions of the
+// GraphQL error for an error returned by GraphJin
+func ErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return ErrCodeNotFound
+	case errors.Is(err, ErrUnauthorized):
+		return ErrCodeUnauthorized
+	case errors.Is(err, ErrValidation):
+		return ErrCodeValidation
+	case errors.Is(err, ErrTimeout):
+		return ErrCodeTimeout
+	case errors.Is(err, ErrDB):
+		return ErrCodeDB
+	case errors.Is(err, ErrRemote):
+		return ErrCodeRemote
+	}
+	return ErrCodeInternal
+}
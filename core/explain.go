@@ -0,0 +1,167 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/dosco/graphjin/core/internal/qcode"
+)
+
+// Explanation struct contains the SQL generated for a query, the parameters
+// bound to it, the role it was compiled for and the query plan returned by
+// the database
+type Explanation struct {
+	Role   string          `json:"role"`
+	SQL    string          `json:"sql"`
+	Params []ExplainParam  `json:"params"`
+	Plan   json.RawMessage `json:"plan"`
+}
+
+// ExplainParam struct is a parameter bound to the SQL query
+type ExplainParam struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// Explain function compiles the query and returns the SQL along with the query plan
+// from the database. On Postgres the query is run with 'EXPLAIN (ANALYZE, FORMAT JSON)'
+// within a transaction that is rolled back so mutations are not saved. On MySQL
+// 'EXPLAIN FORMAT=JSON' is used. Explain is not available when the allow list is enforced.
+func (g *GraphJin) Explain(
+	c context.Context,
+	query string,
+	vars json.RawMessage) (*Explanation, error) {
+	return g.ExplainEx(c, query, "", vars)
+}
+
+// ExplainEx is the extended version of the Explain function, it takes the name of the
+// operation to explain when the query contains more than one operation. If opName is
+// empty the first operation in the query is explained.
+func (g *GraphJin) ExplainEx(
+	c context.Context,
+	query string,
+	opName string,
+	vars json.RawMessage) (*Explanation, error) {
+	return g.engine().explain(c, query, opName, vars)
+}
+
+func (gj *graphjin) explain(
+	c context.Context,
+	query string,
+	opName string,
+	vars json.RawMessage) (*Explanation, error) {
+
+	// compiling arbitrary queries is not allowed in production
+	if gj.conf.EnforceAllowList {
+		return nil, wrapErr(ErrUnauthorized, errors.New("explain: not available when the allow list is enforced"))
+	}
+
+	op, name := qcode.GetQType(query, opName)
+
+	ct := &scontext{
+		Context: c,
+		gj:      gj,
+		op:      op,
+		name:    name,
+	}
+
+	if op == qcode.QTSubscription || op == qcode.QTUnknown {
+		return nil, wrapErr(ErrValidation, errors.New("explain: only queries and mutations are supported"))
+	}
+
+	conn, err := gj.db.Conn(c)
+	if err != nil {
+		return nil, wrapErr(ErrDB, err)
+	}
+	defer conn.Close()
+
+	tx, err := conn.BeginTx(c, nil)
+	if err != nil {
+		return nil, wrapErr(ErrDB, err)
+	}
+	defer tx.Rollback() //nolint: errcheck
+
+	if gj.conf.SetUserID {
+		if err := ct.setLocalUserID(tx); err != nil {
+			return nil, wrapErr(ErrDB, err)
+		}
+	}
+
+	var role string
+
+	switch {
+	case c.Value(UserRoleKey) != nil:
+		role = c.Value(UserRoleKey).(string)
+
+	case gj.abacEnabled:
+		if role, err = ct.executeRoleQuery(tx); err != nil {
+			return nil, wrapErr(ErrDB, err)
+		}
+
+	case keyExists(c, UserIDKey):
+		role = "user"
+
+	default:
+		role = "anon"
+	}
+
+	cq := &cquery{q: rquery{op: op, name: name, query: []byte(query), vars: vars}}
+
+	if op == qcode.QTMutation {
+		err = gj.buildMutationStmts(cq, role)
+	} else {
+		err = gj.buildRoleStmt(cq, role)
+	}
+
+	if err != nil {
+		return nil, compileErr(err)
+	}
+
+	if len(cq.mstmts) != 0 {
+		return nil, wrapErr(ErrValidation, errors.New("explain: multiple root mutations are not supported"))
+	}
+
+	if cq.st.sql == "" {
+		return nil, wrapErr(ErrValidation, errors.New("explain: query does not use the database"))
+	}
+
+	args, err := gj.argList(ct, cq.st.md, vars, nil)
+	if err != nil {
+		return nil, wrapErr(ErrValidation, err)
+	}
+
+	ex := &Explanation{
+		Role:   role,
+		SQL:    cq.st.sql,
+		Params: make([]ExplainParam, len(args.values)),
+	}
+
+	for i, p := range cq.st.md.Params() {
+		ex.Params[i] = ExplainParam{Name: p.Name, Type: p.Type, Value: args.values[i]}
+	}
+
+	timeout := gj.queryTimeout(role)
+
+	if err := ct.setStatementTimeout(tx, timeout); err != nil {
+		return nil, ct.dbErr(err)
+	}
+
+	var q string
+
+	if gj.schema.DBType() == "mysql" {
+		q = "EXPLAIN FORMAT=JSON " + cq.st.sql
+	} else {
+		q = "EXPLAIN (ANALYZE, FORMAT JSON) " + cq.st.sql
+	}
+
+	var plan []byte
+
+	if err := tx.QueryRowContext(ct, q, args.values...).Scan(&plan); err != nil {
+		return nil, ct.dbErr(err)
+	}
+	ex.Plan = json.RawMessage(plan)
+
+	return ex, nil
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/dosco/graphjin/core/internal/qcode"
+)
+
+// Hooks interface is used to intercept a request at various stages of its
+// lifecycle. Returning an error from any of the functions stops the request
+// and the error is returned to the client. Errors wrapping ErrUnauthorized or
+// ErrValidation are reported with the matching error code.
+//
+// Embed NopHooks to only implement the functions needed.
+type Hooks interface {
+	// OnCompile is called once the query has been compiled, before any
+	// SQL is executed
+	OnCompile(c context.Context, q *QueryInfo) error
+
+	// BeforeExecute is called before each SQL statement is executed, the SQL
+	// and the arguments can be modified
+	BeforeExecute(c context.Context, st *Statement) error
+
+	// AfterExecute is called with the result before it's returned, the result
+	// can be modified
+	AfterExecute(c context.Context, res *Result) error
+
+	// OnMutation is called after each root field of a mutation is executed
+	// with the table, operation (insert, update, upsert, delete or call) and the
+	// rows returned. Mutations are executed within a transaction that
+	// is rolled back if an error is returned
+	OnMutation(c context.Context, table, op string, rows json.RawMessage) error
+}
+
+// NopHooks implements all the functions of the Hooks interface as no-ops
+type NopHooks struct{}
+
+func (NopHooks) OnCompile(context.Context, *QueryInfo) error     { return nil }
+func (NopHooks) BeforeExecute(context.Context, *Statement) error { return nil }
+func (NopHooks) AfterExecute(context.Context, *Result) error     { return nil }
+func (NopHooks) OnMutation(context.Context, string, string, json.RawMessage) error {
+	return nil
+}
+
+// QueryInfo struct describes a compiled query
+type QueryInfo struct {
+	Name      string
+	Operation OpType
+	Role      string
+	Selects   []SelectInfo
+}
+
+// SelectInfo struct describes a selector (table or relationship) in a
+// compiled query
+type SelectInfo struct {
+	ID        int32
+	ParentID  int32
+	FieldName string
+	Table     string
+	Columns   []string
+}
+
+// Statement struct contains an SQL statement and its arguments
+type Statement struct {
+	SQL  string
+	Args []interface{}
+}
+
+func (c *scontext) onCompile(cq *cquery, role string) error {
+	h := c.gj.conf.Hooks
+	if h == nil {
+		return nil
+	}
+
+	q := QueryInfo{
+		Name:      c.name,
+		Operation: opType(c.op),
+		Role:      role,
+	}
+
+	stmts := cq.mstmts
+	if len(stmts) == 0 {
+		stmts = []stmt{cq.st}
+	}
+
+	for _, st := range stmts {
+		for _, sel := range st.qc.Selects {
+			cols := make([]string, len(sel.Cols))
+			for i, col := range sel.Cols {
+				cols[i] = col.FieldName
+			}
+
+			q.Selects = append(q.Selects, SelectInfo{
+				ID:        sel.ID,
+				ParentID:  sel.ParentID,
+				FieldName: sel.FieldName,
+				Table:     sel.Table,
+				Columns:   cols,
+			})
+		}
+	}
+
+	return h.OnCompile(c, &q)
+}
+
+// beforeExecute returns the SQL and arguments to execute
+// after passing them through the BeforeExecute hook
+func (c *scontext) beforeExecute(sql string, args []interface{}) (string, []interface{}, error) {
+	h := c.gj.conf.Hooks
+	if h == nil {
+		return sql, args, nil
+	}
+
+	st := Statement{SQL: sql, Args: args}
+
+	if err := h.BeforeExecute(c, &st); err != nil {
+		return "", nil, err
+	}
+	return st.SQL, st.Args, nil
+}
+
+// onMutation passes the rows returned by the root field of
+// a mutation to the OnMutation hook
+func (c *scontext) onMutation(qc *qcode.QCode, data []byte) error {
+	h := c.gj.conf.Hooks
+	if h == nil || qc.Type != qcode.QTMutation {
+		return nil
+	}
+
+	sel := qc.Selects[0]
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	op := strings.ToLower(strings.TrimPrefix(qc.SType.String(), "QT"))
+	if sel.TableFunc != nil {
+		op = "call"
+	}
+
+	return h.OnMutation(c, sel.Table, op, m[sel.FieldName])
+}
+
+func opType(qt qcode.QType) OpType {
+	switch qt {
+	case qcode.QTQuery:
+		return OpQuery
+	case qcode.QTSubscription:
+		return OpSubscription
+	case qcode.QTMutation:
+		return OpMutation
+	}
+	return OpUnknown
+}
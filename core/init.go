@@ -10,15 +10,9 @@ import (
 	"github.com/gobuffalo/flect"
 )
 
-func (gj *GraphJin) initConfig() error {
+func (gj *graphjin) initConfig() error {
 	c := gj.conf
 
-	if gj.conf.EnableInflection {
-		if err := initInflection(c); err != nil {
-			return err
-		}
-	}
-
 	tm := make(map[string]struct{})
 
 	for _, t := range c.Tables {
@@ -154,7 +148,22 @@ func addTables(conf *Config, di *sdata.DBInfo) error {
 }
 
 func updateTable(conf *Config, di *sdata.DBInfo, t Table) error {
+	if t.Description != "" {
+		t1, err := di.GetTable(t.Schema, t.Name)
+		if err != nil {
+			return fmt.Errorf("table: %s.%s: %w", t.Schema, t.Name, err)
+		}
+		t1.Comment = t.Description
+	}
+
 	for _, c := range t.Columns {
+		if c.SQL != "" {
+			if err := addComputedColumn(di, t, c); err != nil {
+				return err
+			}
+			continue
+		}
+
 		t1, err := di.GetTable(t.Schema, t.Name)
 		if err != nil {
 			return fmt.Errorf("table: %s.%s: %w", t.Schema, t.Name, err)
@@ -165,6 +174,10 @@ func updateTable(conf *Config, di *sdata.DBInfo, t Table) error {
 			return err
 		}
 
+		if c.Description != "" {
+			c1.Comment = c.Description
+		}
+
 		if c.Primary {
 			c1.PrimaryKey = true
 			t1.PrimaryCol = *c1
@@ -178,6 +191,27 @@ func updateTable(conf *Config, di *sdata.DBInfo, t Table) error {
 	return nil
 }
 
+func addComputedColumn(di *sdata.DBInfo, t Table, c Column) error {
+	typ := c.Type
+	if typ == "" {
+		typ = "text"
+	}
+
+	err := di.AddColumn(sdata.DBColumn{
+		Schema:  t.Schema,
+		Table:   t.Name,
+		Name:    c.Name,
+		Type:    typ,
+		Array:   c.Array,
+		Comment: c.Description,
+		SQL:     c.SQL,
+	})
+	if err != nil {
+		return fmt.Errorf("computed column: %w", err)
+	}
+	return nil
+}
+
 func addJsonTable(conf *Config, di *sdata.DBInfo, t Table) error {
 	// This is for jsonb column that want to be a table.
 	if t.Table == "" {
@@ -205,11 +239,12 @@ func addJsonTable(conf *Config, di *sdata.DBInfo, t Table) error {
 	for i := range t.Columns {
 		c := t.Columns[i]
 		columns = append(columns, sdata.DBColumn{
-			Schema: bc.Schema,
-			Table:  t.Name,
-			Name:   c.Name,
-			Key:    strings.ToLower(c.Name),
-			Type:   c.Type,
+			Schema:  bc.Schema,
+			Table:   t.Name,
+			Name:    c.Name,
+			Key:     strings.ToLower(c.Name),
+			Type:    c.Type,
+			Comment: c.Description,
 		})
 		if c.Type == "" {
 			return fmt.Errorf("json table: type parameter missing for column: %s.%s'",
@@ -229,6 +264,7 @@ func addJsonTable(conf *Config, di *sdata.DBInfo, t Table) error {
 	nt := sdata.NewDBTable(bc.Schema, t.Name, bc.Type, columns)
 	nt.PrimaryCol = col1
 	nt.SecondaryCol = bt.PrimaryCol
+	nt.Comment = t.Description
 
 	di.AddTable(nt)
 	return nil
@@ -321,6 +357,13 @@ func addForeignKey(conf *Config, di *sdata.DBInfo, c Column, t Table) error {
 
 func addRoles(c *Config, qc *qcode.Compiler) error {
 	for _, r := range c.Roles {
+		qc.SetRoleLimits(r.Name, qcode.LimitsConfig{
+			MaxDepth:     r.MaxDepth,
+			MaxSelectors: r.MaxSelectors,
+			MaxRows:      r.MaxRows,
+			MaxCost:      r.MaxCost,
+		})
+
 		for _, t := range r.Tables {
 			if err := addRole(qc, r, t, c.DefaultBlock); err != nil {
 				return err
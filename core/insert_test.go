@@ -493,3 +493,142 @@ func Example_insertIntoRecursiveRelationshipAndConnectTable2() {
 	}
 	// Output: {"comments": {"id": 5004, "product": {"id": 26}, "comments": [{"id": 6}], "commenter": {"id": 3}}}
 }
+
+func Example_insertInTransaction() {
+	gql := `mutation {
+		users(insert: $data) {
+			id
+			email
+		}
+	}`
+
+	vars := json.RawMessage(`{
+		"data": {
+			"id": 1020,
+			"email": "user1020@test.com",
+			"full_name": "User 1020",
+			"stripe_id": "payment_id_1020",
+			"category_counts": [{"category_id": 1, "count": 400},{"category_id": 2, "count": 600}]
+		}
+	}`)
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := context.WithValue(context.Background(), core.UserIDKey, 3)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		panic(err)
+	}
+	defer tx.Rollback() //nolint: errcheck
+
+	res, err := gj.GraphQLTx(ctx, tx, gql, vars, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(string(res.Data))
+	}
+	// Output: {"users": [{"id": 1020, "email": "user1020@test.com"}]}
+}
+
+func Example_insertMultipleRoots() {
+	gql := `mutation {
+		user: users(insert: $user) {
+			id
+			email
+		}
+		product: products(insert: $product) {
+			id
+			name
+		}
+	}`
+
+	vars := json.RawMessage(`{
+		"user": {
+			"id": 1021,
+			"email": "user1021@test.com",
+			"full_name": "User 1021",
+			"stripe_id": "payment_id_1021",
+			"category_counts": [{"category_id": 1, "count": 400},{"category_id": 2, "count": 600}]
+		},
+		"product": {
+			"id": 2021,
+			"name": "Product 2021",
+			"description": "Description for product 2021",
+			"price": 2031.5,
+			"tags": ["Tag 1", "Tag 2"],
+			"category_ids": [1, 2, 3, 4, 5]
+		}
+	}`)
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := context.WithValue(context.Background(), core.UserIDKey, 3)
+	res, err := gj.GraphQL(ctx, gql, vars, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(string(res.Data))
+	}
+	// Output: {"user": [{"id": 1021, "email": "user1021@test.com"}], "product": [{"id": 2021, "name": "Product 2021"}]}
+}
+
+func Example_insertWithRootFieldMutation() {
+	gql := `mutation {
+		users(insert: $data) {
+			id
+		}
+		notify(id: 1022)
+	}`
+
+	vars := json.RawMessage(`{
+		"data": {
+			"id": 1022,
+			"email": "user1022@test.com",
+			"full_name": "User 1022",
+			"stripe_id": "payment_id_1022",
+			"category_counts": [{"category_id": 1, "count": 400},{"category_id": 2, "count": 600}]
+		}
+	}`)
+
+	// the hooks make the mutation run in a transaction
+	conf := &core.Config{DBType: dbType, DisableAllowList: true, Hooks: core.NopHooks{}}
+	conf.RootFields = []core.RootField{{
+		Name:     "notify",
+		Mutation: true,
+		Type:     "String",
+		Args:     []core.RootFieldArg{{Name: "id", Type: "Int!"}},
+		Fn: func(c context.Context, args json.RawMessage) ([]byte, error) {
+			// root field mutations are called once the database
+			// mutations are committed so the user is found
+			var email string
+			err := db.QueryRowContext(c, "SELECT email FROM users WHERE id = 1022").Scan(&email)
+			if err != nil {
+				return nil, err
+			}
+			return json.Marshal(email)
+		},
+	}}
+
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := context.WithValue(context.Background(), core.UserIDKey, 3)
+	res, err := gj.GraphQL(ctx, gql, vars, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(string(res.Data))
+	}
+	// Output: {"users": [{"id": 1022}], "notify": "user1022@test.com"}
+}
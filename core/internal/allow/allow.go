@@ -2,6 +2,8 @@ package allow
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -30,6 +32,10 @@ type Item struct {
 	Query   string
 	Vars    string
 	frags   []Frag
+
+	// hash is the sha256 hash of the query text as sent by the
+	// client, it's used to look up automatic persisted queries
+	hash string
 }
 
 type Frag struct {
@@ -44,6 +50,7 @@ type List struct {
 	filepath     string
 	queryPath    string
 	fragmentPath string
+	hashPath     string
 }
 
 type Config struct {
@@ -70,6 +77,7 @@ func New(filename string, conf Config) (*List, error) {
 
 	al.queryPath = path.Join(ap, "queries")
 	al.fragmentPath = path.Join(ap, "fragments")
+	al.hashPath = path.Join(ap, "hashes")
 
 	if mig {
 		if err := al.migrate(); err != nil {
@@ -94,26 +102,65 @@ func New(filename string, conf Config) (*List, error) {
 	return &al, nil
 }
 
-func (al *List) Set(vars []byte, query string) error {
+// Set saves the operation named opName from the query into the allow list,
+// if opName is empty then the first operation is saved.
+func (al *List) Set(vars []byte, query, opName string) error {
 	if al.saveChan == nil {
 		return errors.New("allow.list is read-only")
 	}
 
+	item, ok, err := newItem(vars, query, opName)
+	if err != nil || !ok {
+		return err
+	}
+
+	al.saveChan <- item
+	return nil
+}
+
+// newItem returns the item to save for the operation named opName
+// from the query, false is returned if the query has no operations
+func newItem(vars []byte, query, opName string) (Item, bool, error) {
 	if query == "" {
-		return errors.New("empty query")
+		return Item{}, false, errors.New("empty query")
 	}
 
 	items, err := parse(query)
 	if err != nil {
-		return err
+		return Item{}, false, err
 	}
 
-	if len(items) != 0 {
-		items[0].Vars = string(vars)
-		al.saveChan <- items[0]
+	if len(items) == 0 {
+		return Item{}, false, nil
 	}
 
-	return nil
+	n := -1
+	for i := range items {
+		if opName == "" || items[i].Name == opName {
+			n = i
+			break
+		}
+	}
+
+	if n == -1 {
+		return Item{}, false, fmt.Errorf("operation not found: %s", opName)
+	}
+
+	item := items[n]
+	item.Vars = string(vars)
+
+	// fragments can be defined anywhere in the query so
+	// collect the ones attached to the other operations
+	for i := range items {
+		if i != n {
+			item.frags = append(item.frags, items[i].frags...)
+		}
+	}
+
+	h := sha256.Sum256([]byte(query))
+	item.hash = hex.EncodeToString(h[:])
+
+	return item, true, nil
 }
 
 func (al *List) loadFile() ([]Item, error) {
@@ -165,6 +212,30 @@ func (al *List) Load() ([]Item, error) {
 	return items, nil
 }
 
+// Hashes returns the names of the saved queries keyed by the sha256 hash
+// (hex encoded) of the query text sent by the clients that saved them
+func (al *List) Hashes() (map[string]string, error) {
+	hm := make(map[string]string)
+
+	files, err := ioutil.ReadDir(al.hashPath)
+	if os.IsNotExist(err) {
+		return hm, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("allow list: %w", err)
+	}
+
+	for _, f := range files {
+		b, err := ioutil.ReadFile(path.Join(al.hashPath, f.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("allow list: %w", err)
+		}
+		hm[f.Name()] = strings.TrimSpace(string(b))
+	}
+
+	return hm, nil
+}
+
 func (al *List) FragmentFetcher() func(name string) (string, error) {
 	return func(name string) (string, error) {
 		v, err := ioutil.ReadFile(path.Join(al.fragmentPath, name))
@@ -224,9 +295,27 @@ func parse(b string) ([]Item, error) {
 			st = expVar
 
 		case isGraphQL(txt):
-			if st == expVar {
-				v := b[sp.Offset:s.Pos().Offset]
+			v := b[sp.Offset:s.Pos().Offset]
+
+			switch st {
+			case expVar:
 				item.Vars = strings.TrimSpace(v[:strings.LastIndexByte(v, '}')+1])
+
+			// multiple operations in the same query
+			case expQuery:
+				item.Query = strings.TrimSpace(v[:strings.LastIndexByte(v, '}')+1])
+				items = append(items, item)
+				item = Item{}
+
+			case expFrag:
+				f := Frag{Value: strings.TrimSpace(v[:strings.LastIndexByte(v, '}')+1])}
+				f.Name = QueryName(f.Value)
+				item.frags = append(item.frags, f)
+
+				if item.Query != "" {
+					items = append(items, item)
+					item = Item{}
+				}
 			}
 			sp = op
 			st = expQuery
@@ -299,7 +388,22 @@ func (al *List) save(item Item) error {
 		return err
 	}
 
-	return nil
+	return al.saveHash(item)
+}
+
+// saveHash saves the name of the query under the hash
+// of the query text sent by the client
+func (al *List) saveHash(item Item) error {
+	if item.hash == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(al.hashPath, os.ModePerm); err != nil {
+		return err
+	}
+
+	fn := path.Join(al.hashPath, item.hash)
+	return ioutil.WriteFile(fn, []byte(item.Name), 0644)
 }
 
 func (al *List) migrate() error {
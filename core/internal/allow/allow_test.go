@@ -1,6 +1,11 @@
 package allow
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path"
 	"testing"
 )
 
@@ -239,3 +244,78 @@ func TestParse2(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestParseMultipleOperations(t *testing.T) {
+	var al = `
+	query getProducts {
+		products {
+			...productFields
+		}
+	}
+
+	fragment productFields on product {
+		id
+		name
+	}
+
+	mutation addProduct {
+		products(insert: $data) {
+			id
+		}
+	}`
+
+	items, err := parse(al)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("expected 2 operations, got %d", len(items))
+	}
+
+	if items[0].Name != "getProducts" || items[1].Name != "addProduct" {
+		t.Fatalf("unexpected operations: %s, %s", items[0].Name, items[1].Name)
+	}
+
+	if len(items[0].frags) != 1 || items[0].frags[0].Name != "productFields" {
+		t.Fatal("fragment 'productFields' missing")
+	}
+}
+
+func TestPersistedQueryHash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "allow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	al, err := New(path.Join(dir, "allow.list"), Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the query text exactly as sent by the client
+	query := `query getProducts {
+		products(limit: 5) { id name }
+	}`
+
+	item, _, err := newItem(nil, query, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := al.saveHash(item); err != nil {
+		t.Fatal(err)
+	}
+
+	hm, err := al.Hashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := sha256.Sum256([]byte(query))
+
+	if name := hm[hex.EncodeToString(h[:])]; name != "getProducts" {
+		t.Fatalf("expected the hash of the query to resolve to 'getProducts' not '%s'", name)
+	}
+}
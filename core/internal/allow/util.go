@@ -17,6 +17,9 @@ func (al *List) makeDir() (string, error) {
 	if err := os.MkdirAll(path.Join(ap, "fragments"), os.ModePerm); err != nil {
 		return ap, err
 	}
+	if err := os.MkdirAll(path.Join(ap, "hashes"), os.ModePerm); err != nil {
+		return ap, err
+	}
 	al.pathExists = true
 	return ap, nil
 }
@@ -2,6 +2,7 @@
 package graph
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"sync"
@@ -62,6 +63,7 @@ type Field struct {
 	Type       FieldType
 	Name       string
 	Alias      string
+	Loc        Location
 	Args       []Arg
 	argsA      [5]Arg
 	Directives []Directive
@@ -69,6 +71,28 @@ type Field struct {
 	childrenA  [5]int32
 }
 
+// Location is the line and column (both starting at 1) of a token
+// in the query text.
+type Location struct {
+	Line   int
+	Column int
+}
+
+// Error is returned by the parser and includes the location in the query
+// where the error was found.
+type Error struct {
+	Loc Location
+	err error
+}
+
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
 type Arg struct {
 	Name string
 	Val  *Node
@@ -85,6 +109,10 @@ type Node struct {
 	Val      string
 	Parent   *Node
 	Children []*Node
+
+	// Label is set on string values that are not quoted
+	// eg. enum values
+	Label bool
 }
 
 var nodePool = sync.Pool{
@@ -111,7 +139,9 @@ type Parser struct {
 	err       error
 }
 
-func Parse(gql []byte, fetchFrag func(name string) (string, error)) (Operation, error) {
+// Parse parses the operation named opName from the query, if opName is empty then
+// the first operation is parsed.
+func Parse(gql []byte, opName string, fetchFrag func(name string) (string, error)) (Operation, error) {
 	var l lexer
 	var op Operation
 	var err error
@@ -120,16 +150,19 @@ func Parse(gql []byte, fetchFrag func(name string) (string, error)) (Operation,
 		return op, errors.New("empty query")
 	}
 
-	if l, err = lex(gql); err != nil {
-		return op, err
-	}
-
 	p := Parser{
 		fetchFrag: fetchFrag,
-		input:     l.input,
 		pos:       -1,
-		items:     l.items,
 	}
+
+	if l, err = lex(gql); err != nil {
+		p.input, p.items = l.input, l.items
+		p.pos = len(l.items) - 1
+		return op, p.error(err)
+	}
+
+	p.input = l.input
+	p.items = l.items
 	op.Fields = op.fieldsA[:0]
 
 	s := -1
@@ -144,11 +177,12 @@ func Parse(gql []byte, fetchFrag func(name string) (string, error)) (Operation,
 		if p.peek(itemFragment) && p.fetchFrag == nil {
 			p.ignore()
 			if _, err := p.parseFragment(); err != nil {
-				return op, err
+				return op, p.error(err)
 			}
 
 		} else {
-			if !qf && p.peek(itemQuery, itemMutation, itemSub, itemObjOpen) {
+			if !qf && p.peek(itemQuery, itemMutation, itemSub, itemObjOpen) &&
+				(opName == "" || p.peekOpName() == opName) {
 				s = p.pos
 				qf = true
 			}
@@ -156,9 +190,13 @@ func Parse(gql []byte, fetchFrag func(name string) (string, error)) (Operation,
 		}
 	}
 
+	if !qf && opName != "" {
+		return op, fmt.Errorf("operation not found: %s", opName)
+	}
+
 	p.reset(s)
 	if op, err = p.parseOp(); err != nil {
-		return op, err
+		return op, p.error(err)
 	}
 
 	for i, f := range op.Fields {
@@ -230,7 +268,7 @@ func (p *Parser) parseOp() (Operation, error) {
 		}
 
 		for {
-			if p.peek(itemEOF, itemFragment) {
+			if p.peek(itemEOF, itemFragment, itemQuery, itemMutation, itemSub) {
 				p.ignore()
 				break
 			}
@@ -376,7 +414,7 @@ func (p *Parser) parseNormalFields(st *Stack, fields []Field) ([]Field, error) {
 		return nil, fmt.Errorf("expecting an alias or field name, got: %s", p.next())
 	}
 
-	fields = append(fields, Field{ID: int32(len(fields))})
+	fields = append(fields, Field{ID: int32(len(fields)), Loc: p.location(p.pos + 1)})
 
 	f := &fields[(len(fields) - 1)]
 	f.Args = f.argsA[:0]
@@ -709,6 +747,7 @@ func (p *Parser) parseValue() (*Node, error) {
 		node.Type = NodeBool
 	case itemName:
 		node.Type = NodeStr
+		node.Label = true
 	case itemVariable:
 		node.Type = NodeVar
 	default:
@@ -770,11 +809,45 @@ func (p *Parser) ignore() {
 	p.pos = n
 }
 
+// peekOpName returns the name of the operation starting at the next token
+func (p *Parser) peekOpName() string {
+	n := p.pos + 2
+	if n >= len(p.items) || p.items[n-1]._type == itemObjOpen {
+		return ""
+	}
+	if p.items[n]._type != itemName {
+		return ""
+	}
+	return b2s(p.items[n].val)
+}
+
 func (p *Parser) peekNext() string {
 	item := p.items[p.pos+1]
 	return b2s(item.val)
 }
 
+// error wraps err with the location of the last token read.
+func (p *Parser) error(err error) error {
+	n := p.pos
+	if n < 0 {
+		n = 0
+	}
+	return &Error{Loc: p.location(n), err: err}
+}
+
+func (p *Parser) location(n int) Location {
+	if n >= len(p.items) {
+		n = len(p.items) - 1
+	}
+	if n < 0 {
+		return Location{Line: 1, Column: 1}
+	}
+	item := p.items[n]
+	ls := bytes.LastIndexByte(p.input[:item.pos], '\n') + 1
+
+	return Location{Line: int(item.line), Column: int(item.pos) - ls + 1}
+}
+
 func (p *Parser) reset(to int) {
 	p.pos = to
 }
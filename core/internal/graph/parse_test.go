@@ -1,6 +1,7 @@
 package graph
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/chirino/graphql/schema"
@@ -50,11 +51,84 @@ fragment userFields2 on user {
 	__typename
 }`)
 
+func TestParseErrorLocation(t *testing.T) {
+	_, err := Parse([]byte(`
+query {
+	users {
+		id
+		email: 
+	}
+}`), "", nil)
+
+	var ge *Error
+	if !errors.As(err, &ge) {
+		t.Fatalf("expected a graph.Error, got: %v", err)
+	}
+
+	if ge.Loc.Line != 5 || ge.Loc.Column != 8 {
+		t.Fatalf("unexpected location: %+v", ge.Loc)
+	}
+}
+
+func TestParseMultipleOperations(t *testing.T) {
+	q := []byte(`
+query getUsers {
+	users {
+		id
+	}
+}
+
+mutation addProduct {
+	products(insert: $data) {
+		id
+	}
+}`)
+
+	op, err := Parse(q, "addProduct", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if op.Type != OpMutate || op.Name != "addProduct" || op.Fields[0].Name != "products" {
+		t.Fatalf("wrong operation parsed: %s %s", op.Type, op.Name)
+	}
+
+	op, err = Parse(q, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if op.Type != OpQuery || op.Name != "getUsers" {
+		t.Fatalf("expected the first operation, got: %s %s", op.Type, op.Name)
+	}
+
+	if _, err := Parse(q, "getProducts", nil); err == nil {
+		t.Fatal(errors.New("expected an error for a missing operation"))
+	}
+}
+
+func TestParseEnumValue(t *testing.T) {
+	op, err := Parse([]byte(`{ products(order: DESC, name: "DESC") { id } }`), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args := op.Fields[0].Args
+
+	if args[0].Val.Val != "DESC" || !args[0].Val.Label {
+		t.Fatalf("expected an enum value: %+v", args[0].Val)
+	}
+
+	if args[1].Val.Val != "DESC" || args[1].Val.Label {
+		t.Fatalf("expected a string value: %+v", args[1].Val)
+	}
+}
+
 func BenchmarkParse(b *testing.B) {
 	b.ResetTimer()
 	b.ReportAllocs()
 	for n := 0; n < b.N; n++ {
-		_, err := Parse(gql, nil)
+		_, err := Parse(gql, "", nil)
 
 		if err != nil {
 			b.Fatal(err)
@@ -68,7 +142,7 @@ func BenchmarkParseP(b *testing.B) {
 
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
-			_, err := Parse(gql, nil)
+			_, err := Parse(gql, "", nil)
 
 			if err != nil {
 				b.Fatal(err)
@@ -81,7 +155,7 @@ func BenchmarkParseFragment(b *testing.B) {
 	b.ResetTimer()
 	b.ReportAllocs()
 	for n := 0; n < b.N; n++ {
-		_, err := Parse(gqlWithFragments, nil)
+		_, err := Parse(gqlWithFragments, "", nil)
 
 		if err != nil {
 			b.Fatal(err)
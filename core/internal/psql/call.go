@@ -0,0 +1,53 @@
+//nolint:errcheck
+
+package psql
+
+import (
+	"bytes"
+
+	"github.com/dosco/graphjin/core/internal/qcode"
+)
+
+// compileCall compiles a mutation that calls a database function or stored
+// procedure. The rows returned by functions returning table rows are selected
+// like any other query.
+func (co *Compiler) compileCall(
+	w *bytes.Buffer,
+	qc *qcode.QCode,
+	md *Metadata) {
+
+	sel := &qc.Selects[0]
+
+	if !sel.TableFunc.Value {
+		co.CompileQuery(w, qc, md)
+		return
+	}
+
+	md.ct = qc.Schema.DBType()
+
+	c := compilerContext{
+		md:       md,
+		w:        w,
+		qc:       qc,
+		Compiler: co,
+	}
+
+	switch c.ct {
+	case "mysql":
+		// procedures cannot be called within a select
+		md.exec = true
+		c.w.WriteString(`CALL `)
+		c.renderFuncCall(sel.TableFunc)
+
+	default:
+		c.w.WriteString(`SELECT jsonb_build_object(`)
+		c.squoted(sel.FieldName)
+		if sel.TableFunc.ReturnType == "" {
+			c.w.WriteString(`, NULL) FROM `)
+		} else {
+			c.w.WriteString(`, __fn.r) FROM `)
+		}
+		c.renderFuncCall(sel.TableFunc)
+		c.w.WriteString(` AS __fn(r)`)
+	}
+}
@@ -155,10 +155,10 @@ func (c *compilerContext) renderFunctionSearchHeadline(sel *qcode.Select, fn qco
 
 	c.w.WriteString(`ts_headline(`)
 	if hasIndex {
-		colWithTable(c.w, sel.Table, fn.Col.Name)
+		c.renderCol(sel.Table, fn.Col)
 	} else {
 		c.w.WriteString(`to_tsvector(`)
-		colWithTable(c.w, sel.Table, fn.Col.Name)
+		c.renderCol(sel.Table, fn.Col)
 		c.w.WriteString(`)`)
 	}
 	if c.cv >= 110000 {
@@ -174,7 +174,7 @@ func (c *compilerContext) renderFunctionSearchHeadline(sel *qcode.Select, fn qco
 func (c *compilerContext) renderOtherFunction(sel *qcode.Select, fn qcode.Function) {
 	c.w.WriteString(fn.Name)
 	c.w.WriteString(`(`)
-	colWithTable(c.w, sel.Table, fn.Col.Name)
+	c.renderCol(sel.Table, fn.Col)
 	_, _ = c.w.WriteString(`)`)
 }
 
@@ -185,12 +185,22 @@ func (c *compilerContext) renderBaseColumns(sel *qcode.Select) {
 		if i != 0 {
 			c.w.WriteString(`, `)
 		}
-		if col.Col.Array && c.ct == "mysql" {
+		switch {
+		case col.Col.SQL != "":
+			c.renderCol(sel.Table, col.Col)
+			c.alias(col.Col.Name)
+
+		case truncUnit(sel, col.Col) != "":
+			c.renderGroupCol(sel, col.Col)
+			c.alias(col.Col.Name)
+
+		case col.Col.Array && c.ct == "mysql":
 			c.w.WriteString(`CAST(`)
 			colWithTable(c.w, sel.Table, col.Col.Name)
 			c.w.WriteString(` AS JSON) AS `)
 			c.w.WriteString(col.Col.Name)
-		} else {
+
+		default:
 			colWithTable(c.w, sel.Table, col.Col.Name)
 		}
 		i++
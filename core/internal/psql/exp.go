@@ -125,7 +125,7 @@ func (c *expContext) renderOp(ex *qcode.Exp) {
 		if ex.Type == qcode.ValRef && ex.Op == qcode.OpIsNull {
 			colWithTable(c.w, ex.Table, ex.Col.Name)
 		} else {
-			colWithTable(c.w, c.ti.Name, ex.Col.Name)
+			c.renderExpCol(ex)
 		}
 		c.w.WriteString(`) `)
 	}
@@ -248,6 +248,19 @@ func (c *expContext) renderOp(ex *qcode.Exp) {
 	c.w.WriteString(`)`)
 }
 
+// renderExpCol renders the column of the expression, on having
+// expressions the aggregate function is applied to it
+func (c *expContext) renderExpCol(ex *qcode.Exp) {
+	if ex.Func == "" {
+		c.renderCol(c.ti.Name, ex.Col)
+		return
+	}
+	c.w.WriteString(ex.Func)
+	c.w.WriteString(`(`)
+	c.renderCol(c.ti.Name, ex.Col)
+	c.w.WriteString(`)`)
+}
+
 func (c *expContext) renderValPrefix(ex *qcode.Exp) bool {
 	if ex.Type == qcode.ValVar {
 		return c.renderValVarPrefix(ex)
@@ -261,7 +274,7 @@ func (c *expContext) renderValVarPrefix(ex *qcode.Exp) bool {
 			c.w.WriteString(`JSON_CONTAINS(`)
 			c.renderParam(Param{Name: ex.Val, Type: ex.Col.Type, IsArray: true})
 			c.w.WriteString(`, CAST(`)
-			colWithTable(c.w, c.ti.Name, ex.Col.Name)
+			c.renderExpCol(ex)
 			c.w.WriteString(` AS JSON), '$')`)
 			return true
 		}
@@ -86,6 +86,12 @@ func (md Metadata) Params() []Param {
 	return md.params
 }
 
+// Exec returns true if the statement returns no rows and should be
+// executed, like the call of a stored procedure on MySQL
+func (md Metadata) Exec() bool {
+	return md.exec
+}
+
 func parseVar(v string) (string, string) {
 	dt := "text"
 	if n := strings.IndexByte(v, ':'); n != -1 {
@@ -111,11 +111,35 @@ func delete(t *testing.T) {
 // 	}
 // }
 
+func functionCall(t *testing.T) {
+	gql := `mutation {
+		set_price(product_id: $id, new_price: 9.99) {
+			id
+			price
+			users {
+				email
+			}
+		}
+	}`
+
+	compileGQLToPSQL(t, gql, nil, "user")
+}
+
+func functionCallValue(t *testing.T) {
+	gql := `mutation {
+		close_account(user_id: $user_id)
+	}`
+
+	compileGQLToPSQL(t, gql, nil, "user")
+}
+
 func TestCompileMutate(t *testing.T) {
 	t.Run("singleUpsert", singleUpsert)
 	t.Run("singleUpsertWhere", singleUpsertWhere)
 	// t.Run("bulkUpsert", bulkUpsert)
 	t.Run("delete", delete)
+	t.Run("functionCall", functionCall)
+	t.Run("functionCallValue", functionCallValue)
 	// t.Run("blockedInsert", blockedInsert)
 	// t.Run("blockedUpdate", blockedUpdate)
 }
@@ -81,7 +81,7 @@ func TestMain(m *testing.M) {
 
 	err = qcompile.AddRole("user", "public", "users", qcode.TRConfig{
 		Query: qcode.QueryConfig{
-			Columns: []string{"id", "full_name", "avatar", "email", "products"},
+			Columns: []string{"id", "full_name", "avatar", "email", "display_name", "products"},
 		},
 	})
 	if err != nil {
@@ -124,6 +124,12 @@ func TestMain(m *testing.M) {
 		log.Fatal(err)
 	}
 
+	for _, fn := range []string{"set_price", "product_count", "close_account"} {
+		if err := qcompile.AddProcedure(fn, nil); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	vars := map[string]string{
 		"admin_account_id": "5",
 		"get_price":        "sql:select price from prices where id = $product_id",
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/dosco/graphjin/core/internal/qcode"
 	"github.com/dosco/graphjin/core/internal/sdata"
@@ -23,6 +24,7 @@ type Param struct {
 type Metadata struct {
 	ct     string
 	poll   bool
+	exec   bool
 	params []Param
 	pindex map[string]int
 }
@@ -78,7 +80,11 @@ func (co *Compiler) Compile(w *bytes.Buffer, qc *qcode.QCode) (Metadata, error)
 		co.CompileQuery(w, qc, &md)
 
 	case qcode.QTMutation:
-		co.compileMutation(w, qc, &md)
+		if len(qc.Selects) != 0 && qc.Selects[0].TableFunc != nil {
+			co.compileCall(w, qc, &md)
+		} else {
+			co.compileMutation(w, qc, &md)
+		}
 
 	default:
 		err = fmt.Errorf("Unknown operation type %d", qc.Type)
@@ -350,6 +356,7 @@ func (c *compilerContext) renderBaseSelect(sel *qcode.Select) {
 	}
 
 	c.renderGroupBy(sel)
+	c.renderHaving(sel)
 	c.renderOrderBy(sel)
 	c.renderLimit(sel)
 }
@@ -440,7 +447,11 @@ func (c *compilerContext) renderFrom(sel *qcode.Select) {
 		c.quoted(sel.Table)
 
 	default:
-		c.quoted(sel.Table)
+		if sel.TableFunc != nil {
+			c.renderTableFunc(sel)
+		} else {
+			c.quoted(sel.Table)
+		}
 	}
 
 	if sel.Paging.Cursor {
@@ -448,6 +459,36 @@ func (c *compilerContext) renderFrom(sel *qcode.Select) {
 	}
 }
 
+// renderTableFunc renders the call to the function returning the
+// rows of the selector, the rows are aliased as the table
+func (c *compilerContext) renderTableFunc(sel *qcode.Select) {
+	c.renderFuncCall(sel.TableFunc)
+	c.w.WriteString(` AS `)
+	c.quoted(sel.Table)
+}
+
+func (c *compilerContext) renderFuncCall(fn *qcode.TableFunc) {
+	c.quoted(fn.Name)
+	c.w.WriteString(`(`)
+
+	for i, a := range fn.Args {
+		if i != 0 {
+			c.w.WriteString(`, `)
+		}
+
+		switch a.Type {
+		case qcode.ValVar:
+			c.renderParam(Param{Name: a.Val, Type: a.DBType})
+		case qcode.ValStr:
+			c.squoted(strings.ReplaceAll(a.Val, `'`, `''`))
+		default:
+			c.w.WriteString(a.Val)
+		}
+	}
+
+	c.w.WriteString(`)`)
+}
+
 func (c *compilerContext) renderJSONTable(sel *qcode.Select) {
 	c.w.WriteString(`JSON_TABLE(`)
 	colWithTable(c.w, sel.Rel.Left.Col.Table, sel.Rel.Left.Col.Name)
@@ -558,6 +599,18 @@ func (c *compilerContext) renderWhere(sel *qcode.Select) {
 }
 
 func (c *compilerContext) renderGroupBy(sel *qcode.Select) {
+	if len(sel.GroupBy) != 0 {
+		c.w.WriteString(` GROUP BY `)
+
+		for i, gb := range sel.GroupBy {
+			if i != 0 {
+				c.w.WriteString(`, `)
+			}
+			c.renderGroupCol(sel, gb.Col)
+		}
+		return
+	}
+
 	if !sel.GroupCols {
 		return
 	}
@@ -567,8 +620,79 @@ func (c *compilerContext) renderGroupBy(sel *qcode.Select) {
 		if i != 0 {
 			c.w.WriteString(`, `)
 		}
-		colWithTable(c.w, sel.Table, col.Col.Name)
+		c.renderCol(sel.Table, col.Col)
+	}
+}
+
+func (c *compilerContext) renderHaving(sel *qcode.Select) {
+	if sel.Having == nil {
+		return
+	}
+	c.w.WriteString(` HAVING `)
+	c.renderExp(sel.Ti, sel.Having, false)
+}
+
+// renderGroupCol renders the column, columns grouped by a unit
+// of time are truncated to the start of it
+func (c *compilerContext) renderGroupCol(sel *qcode.Select, col sdata.DBColumn) {
+	unit := truncUnit(sel, col)
+	if unit == "" {
+		c.renderCol(sel.Table, col)
+		return
+	}
+
+	if c.ct != "mysql" {
+		c.w.WriteString(`date_trunc(`)
+		c.squoted(unit)
+		c.w.WriteString(`, `)
+		c.renderCol(sel.Table, col)
+		c.w.WriteString(`)`)
+		return
+	}
+
+	switch unit {
+	case "week":
+		c.w.WriteString(`CAST(DATE_SUB(DATE(`)
+		c.renderCol(sel.Table, col)
+		c.w.WriteString(`), INTERVAL WEEKDAY(`)
+		c.renderCol(sel.Table, col)
+		c.w.WriteString(`) DAY) AS DATETIME)`)
+
+	case "quarter":
+		c.w.WriteString(`CAST(MAKEDATE(YEAR(`)
+		c.renderCol(sel.Table, col)
+		c.w.WriteString(`), 1) + INTERVAL (QUARTER(`)
+		c.renderCol(sel.Table, col)
+		c.w.WriteString(`) - 1) QUARTER AS DATETIME)`)
+
+	default:
+		c.w.WriteString(`CAST(DATE_FORMAT(`)
+		c.renderCol(sel.Table, col)
+		c.w.WriteString(`, `)
+		c.squoted(mysqlTruncFormats[unit])
+		c.w.WriteString(`) AS DATETIME)`)
+	}
+}
+
+// mysqlTruncFormats are the formats used to truncate
+// timestamps on MySQL which has no date_trunc
+var mysqlTruncFormats = map[string]string{
+	"second": "%Y-%m-%d %H:%i:%s",
+	"minute": "%Y-%m-%d %H:%i:00",
+	"hour":   "%Y-%m-%d %H:00:00",
+	"day":    "%Y-%m-%d",
+	"month":  "%Y-%m-01",
+	"year":   "%Y-01-01",
+}
+
+// truncUnit returns the unit of time the column is grouped by if any
+func truncUnit(sel *qcode.Select, col sdata.DBColumn) string {
+	for _, gb := range sel.GroupBy {
+		if gb.Col.Name == col.Name {
+			return gb.Trunc
+		}
 	}
+	return ""
 }
 
 func (c *compilerContext) renderOrderBy(sel *qcode.Select) {
@@ -580,7 +704,7 @@ func (c *compilerContext) renderOrderBy(sel *qcode.Select) {
 		if i != 0 {
 			c.w.WriteString(`, `)
 		}
-		colWithTable(c.w, sel.Table, col.Col.Name)
+		c.renderGroupCol(sel, col.Col)
 
 		switch col.Order {
 		case qcode.OrderAsc:
@@ -608,7 +732,7 @@ func (c *compilerContext) renderDistinctOn(sel *qcode.Select) {
 		if i != 0 {
 			c.w.WriteString(`, `)
 		}
-		colWithTable(c.w, sel.Table, col.Name)
+		c.renderCol(sel.Table, col)
 	}
 	c.w.WriteString(`) `)
 }
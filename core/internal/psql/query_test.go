@@ -647,6 +647,86 @@ func blockedFunctions(t *testing.T) {
 	compileGQLToPSQLExpectErr(t, gql, nil, "bad_dude")
 }
 
+func computedColumns(t *testing.T) {
+	gql := `query {
+		users(where: { display_name: { ilike: "%@example.com>" } }, order_by: { display_name: asc }) {
+			id
+			display_name
+		}
+	}`
+
+	compileGQLToPSQL(t, gql, nil, "user")
+}
+
+func computedColumnsWithJoin(t *testing.T) {
+	gql := `query {
+		products(where: { users: { display_name: { ilike: "%@example.com>" } } }) {
+			id
+			users {
+				display_name
+			}
+		}
+	}`
+
+	qc, err := qcompile.Compile([]byte(gql), nil, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, sql, err := pcompile.CompileEx(qc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the expression is used in the filter on the joined table
+	// and in the nested select
+	exp := []byte(`(users.full_name || ' <' || users.email || '>')`)
+
+	if n := bytes.Count(sql, exp); n != 2 {
+		t.Fatalf("expected the computed column twice, got %d: %s", n, sql)
+	}
+}
+
+func tableFunctions(t *testing.T) {
+	gql := `query {
+		search_products(term: $term, max_price: 20.5, order_by: { price: desc }, limit: 5) {
+			id
+			name
+			users {
+				full_name
+			}
+		}
+	}`
+
+	compileGQLToPSQL(t, gql, nil, "user")
+}
+
+func groupBy(t *testing.T) {
+	gql := `query {
+		products(group_by: [name]) {
+			name
+			count_id
+			avg_price
+		}
+	}`
+
+	compileGQLToPSQL(t, gql, nil, "user")
+}
+
+func groupByTimeWithHaving(t *testing.T) {
+	gql := `query {
+		products(
+			group_by: { date_trunc: { field: created_at, unit: day } },
+			having: { and: [{ count_id: { gt: 5 } }, { sum_price: { lt: $max } }] },
+			order_by: { created_at: desc }) {
+			created_at
+			count_id
+		}
+	}`
+
+	compileGQLToPSQL(t, gql, nil, "admin")
+}
+
 func TestCompileQuery(t *testing.T) {
 	t.Run("simpleQuery", simpleQuery)
 	t.Run("withVariableLimit", withVariableLimit)
@@ -688,6 +768,11 @@ func TestCompileQuery(t *testing.T) {
 	t.Run("nullForAuthRequiredInAnon", nullForAuthRequiredInAnon)
 	t.Run("blockedQuery", blockedQuery)
 	t.Run("blockedFunctions", blockedFunctions)
+	t.Run("computedColumns", computedColumns)
+	t.Run("computedColumnsWithJoin", computedColumnsWithJoin)
+	t.Run("tableFunctions", tableFunctions)
+	t.Run("groupBy", groupBy)
+	t.Run("groupByTimeWithHaving", groupByTimeWithHaving)
 }
 
 var benchGQL = []byte(`query {
@@ -3,6 +3,8 @@ package psql
 import (
 	"bytes"
 	"strconv"
+
+	"github.com/dosco/graphjin/core/internal/sdata"
 )
 
 func (c *compilerContext) alias(alias string) {
@@ -23,6 +25,19 @@ func colWithTable(w *bytes.Buffer, table, col string) {
 	w.WriteString(col)
 }
 
+// renderCol renders a column of the table or the SQL expression of a
+// computed column, the expression is rendered as is so its columns must
+// be qualified with the table name to not be ambiguous in joins
+func (c *compilerContext) renderCol(table string, col sdata.DBColumn) {
+	if col.SQL == "" {
+		colWithTable(c.w, table, col.Name)
+		return
+	}
+	c.w.WriteString(`(`)
+	c.w.WriteString(col.SQL)
+	c.w.WriteString(`)`)
+}
+
 func colWithTableID(w *bytes.Buffer, table string, id int32, col string) {
 	w.WriteString(table)
 	if id >= 0 {
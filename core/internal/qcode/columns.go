@@ -17,6 +17,15 @@ func (co *Compiler) compileColumns(
 	field graph.Field,
 	tr trval) error {
 
+	// functions called by mutations that don't
+	// return table rows only return a value
+	if sel.TableFunc != nil && sel.TableFunc.Value {
+		if len(field.Children) != 0 {
+			return fmt.Errorf("function '%s' does not return rows", sel.TableFunc.Name)
+		}
+		return nil
+	}
+
 	sel.Cols = make([]Column, 0, len(field.Children))
 	sel.BCols = make([]Column, 0, len(field.Children))
 
@@ -60,6 +69,12 @@ func (co *Compiler) compileChildColumns(
 		// these later to strip the response json
 		if sel.Rel.Type == sdata.RelRemote {
 			sel.Cols = append(sel.Cols, Column{FieldName: fname})
+
+			rf, err := newRemoteField(op, f)
+			if err != nil {
+				return err
+			}
+			sel.Fields = append(sel.Fields, rf)
 			continue
 		}
 
@@ -86,7 +101,7 @@ func (co *Compiler) compileChildColumns(
 				return err
 			}
 			if dbc.Blocked {
-				return fmt.Errorf("column: '%s.%s.%s' blocked",
+				return blockedErr("column: '%s.%s.%s' blocked",
 					dbc.Schema, dbc.Table, dbc.Name)
 			}
 			// is a function
@@ -99,7 +114,7 @@ func (co *Compiler) compileChildColumns(
 		}
 	}
 
-	if aggExist && len(sel.Cols) != 0 {
+	if (aggExist || sel.Having != nil) && len(sel.Cols) != 0 {
 		sel.GroupCols = true
 	}
 
@@ -177,12 +192,12 @@ func (co *Compiler) orderByIDCol(sel *Select) error {
 func validateSelector(qc *QCode, sel *Select, tr trval) error {
 	for _, col := range sel.Cols {
 		if !tr.columnAllowed(qc, col.Col.Name) {
-			return fmt.Errorf("column blocked: %s (%s)", col.Col.Name, tr.role)
+			return blockedErr("column blocked: %s (%s)", col.Col.Name, tr.role)
 		}
 	}
 
 	if len(sel.Funcs) != 0 && tr.isFuncsBlocked() {
-		return fmt.Errorf("functions blocked: %s (%s)", sel.Funcs[0].Col.Name, tr.role)
+		return blockedErr("functions blocked: %s (%s)", sel.Funcs[0].Col.Name, tr.role)
 	}
 
 	for _, fn := range sel.Funcs {
@@ -195,7 +210,7 @@ func validateSelector(qc *QCode, sel *Select, tr trval) error {
 		}
 
 		if blocked {
-			return fmt.Errorf("column blocked: %s (%s)", fn.Name, tr.role)
+			return blockedErr("column blocked: %s (%s)", fn.Name, tr.role)
 		}
 	}
 	return nil
@@ -1,7 +1,6 @@
 package qcode
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/gobuffalo/flect"
@@ -15,6 +14,10 @@ type Config struct {
 	defTrv           trval
 	EnableInflection bool
 	DBSchema         string
+
+	// TypeMap maps database types to GraphQL scalar types,
+	// it overrides the built-in mappings
+	TypeMap map[string]string
 }
 
 type TRConfig struct {
@@ -242,7 +245,7 @@ func (trv *trval) isBlocked(qt QType, name string) error {
 		blocked = trv.delete.block
 	}
 	if blocked {
-		return fmt.Errorf("%s blocked: %s (%s)", qt, name, trv.role)
+		return blockedErr("%s blocked: %s (%s)", qt, name, trv.role)
 	}
 	return nil
 }
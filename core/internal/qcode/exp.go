@@ -22,6 +22,10 @@ type aexpst struct {
 	st       *util.StackInf
 	ti       sdata.DBTable
 	savePath bool
+
+	// having is set when the keys of the expression
+	// are aggregate functions eg. count_id
+	having bool
 }
 
 type aexp struct {
@@ -36,6 +40,11 @@ func (co *Compiler) compileArgNode(
 	node *graph.Node,
 	savePath bool) (*Exp, bool, error) {
 
+	ast := &aexpst{co: co, st: st, ti: ti, savePath: savePath}
+	return ast.compile(node)
+}
+
+func (ast *aexpst) compile(node *graph.Node) (*Exp, bool, error) {
 	var root *Exp
 	var needsUser bool
 
@@ -43,7 +52,7 @@ func (co *Compiler) compileArgNode(
 		return nil, false, errors.New("invalid argument value")
 	}
 
-	ast := &aexpst{co: co, st: st, ti: ti, savePath: savePath}
+	st := ast.st
 	ast.pushChildren(nil, node)
 
 	for {
@@ -237,9 +246,18 @@ func (ast *aexpst) parseNode(av aexp) (*Exp, error) {
 		if ex.Type, err = getExpType(node); err != nil {
 			return nil, err
 		}
+		if ast.having {
+			if err := ast.setHavingCol(ex, node); err != nil {
+				return nil, err
+			}
+			return ex, nil
+		}
 		if err := setExpColName(ast.co.s, ast.ti, ex, node); err != nil {
 			return nil, err
 		}
+		if err := checkEnumExp(ex); err != nil {
+			return nil, fmt.Errorf("[Where] %w", err)
+		}
 	}
 
 	return ex, nil
@@ -52,6 +52,24 @@ func (co *Compiler) isFunction(sel *Select, fname string) (Function, bool, error
 }
 
 func (co *Compiler) funcPrefixLen(col string) int {
+	if n := aggPrefixLen(col); n != 0 {
+		return n
+	}
+	fnLen := len(col)
+
+	for k := range co.s.GetFunctions() {
+		kLen := len(k)
+		if kLen < fnLen && k[0] == col[0] && strings.HasPrefix(col, k) && col[kLen] == '_' {
+			return kLen + 1
+		}
+	}
+
+	return 0
+}
+
+// aggPrefixLen returns the length of the prefix of
+// the aggregate function eg. count_ of count_id
+func aggPrefixLen(col string) int {
 	switch {
 	case strings.HasPrefix(col, "avg_"):
 		return 4
@@ -76,14 +94,5 @@ func (co *Compiler) funcPrefixLen(col string) int {
 	case strings.HasPrefix(col, "var_samp_"):
 		return 9
 	}
-	fnLen := len(col)
-
-	for k := range co.s.GetFunctions() {
-		kLen := len(k)
-		if kLen < fnLen && k[0] == col[0] && strings.HasPrefix(col, k) && col[kLen] == '_' {
-			return kLen + 1
-		}
-	}
-
 	return 0
 }
@@ -0,0 +1,237 @@
+package qcode
+
+import (
+	"fmt"
+
+	"github.com/dosco/graphjin/core/internal/graph"
+	"github.com/dosco/graphjin/core/internal/sdata"
+	"github.com/dosco/graphjin/core/internal/util"
+)
+
+// GroupBy struct is a column the rows are grouped by, Trunc is set
+// when the column is truncated to a unit of time eg. day
+type GroupBy struct {
+	Col   sdata.DBColumn
+	Trunc string
+}
+
+// compileArgGroupBy compiles the group_by argument, the value is a column,
+// a date_trunc object ({ date_trunc: { field: created_at, unit: day } })
+// or a list of these. Since list values must be of the same type columns
+// can also be set as objects eg. [{ field: name }, { date_trunc: ... }]
+func (co *Compiler) compileArgGroupBy(sel *Select, arg *graph.Arg) error {
+	node := arg.Val
+
+	switch node.Type {
+	case graph.NodeStr, graph.NodeObj:
+		return co.addGroupBy(sel, node)
+
+	case graph.NodeList:
+		for _, cn := range node.Children {
+			if err := co.addGroupBy(sel, cn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return argErr("group_by", "column, object or a list of these")
+}
+
+func (co *Compiler) addGroupBy(sel *Select, node *graph.Node) error {
+	var gb GroupBy
+	var cn string
+
+	switch node.Type {
+	case graph.NodeStr:
+		cn = node.Val
+
+	case graph.NodeObj:
+		if len(node.Children) != 1 {
+			return fmt.Errorf("group_by: expecting a column or date_trunc")
+		}
+
+		switch n := node.Children[0]; n.Name {
+		case "field":
+			cn = n.Val
+		case "date_trunc":
+			var err error
+			if cn, gb.Trunc, err = parseDateTrunc(n); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("group_by: expecting a column or date_trunc")
+		}
+
+	default:
+		return fmt.Errorf("group_by: expecting a column or date_trunc")
+	}
+
+	col, err := sel.Ti.GetColumn(cn)
+	if err != nil {
+		return err
+	}
+
+	if gb.Trunc != "" {
+		if st := co.ScalarType(col); st != "DateTime" && st != "Date" {
+			return fmt.Errorf("date_trunc: column '%s' is not a date or timestamp", col.Name)
+		}
+	}
+
+	for _, v := range sel.GroupBy {
+		if v.Col.Name == col.Name {
+			return fmt.Errorf("duplicate column in group_by: %s", col.Name)
+		}
+	}
+
+	gb.Col = col
+	sel.GroupBy = append(sel.GroupBy, gb)
+	return nil
+}
+
+// parseDateTrunc returns the column and the unit
+// of time of the date_trunc object
+func parseDateTrunc(node *graph.Node) (string, string, error) {
+	var cn, unit string
+
+	for _, n := range node.Children {
+		switch n.Name {
+		case "field":
+			cn = n.Val
+		case "unit":
+			unit = n.Val
+		default:
+			return "", "", fmt.Errorf("date_trunc: invalid argument '%s'", n.Name)
+		}
+	}
+
+	if !isTruncUnit(unit) {
+		return "", "", fmt.Errorf("date_trunc: valid units are second, minute, hour, " +
+			"day, week, month, quarter and year")
+	}
+	return cn, unit, nil
+}
+
+func isTruncUnit(unit string) bool {
+	switch unit {
+	case "second", "minute", "hour", "day", "week", "month", "quarter", "year":
+		return true
+	}
+	return false
+}
+
+// compileArgHaving compiles the having argument, its keys are the
+// aggregate functions applied to the grouped rows eg. count_id
+func (co *Compiler) compileArgHaving(qc *QCode, sel *Select, arg *graph.Arg, tr trval) error {
+	if arg.Val.Type != graph.NodeObj {
+		return fmt.Errorf("expecting an object")
+	}
+
+	ast := &aexpst{co: co, st: util.NewStackInf(), ti: sel.Ti, having: true}
+
+	ex, _, err := ast.compile(arg.Val)
+	if err != nil {
+		return fmt.Errorf("having: %w", err)
+	}
+
+	if err := validateHaving(qc, ex, tr); err != nil {
+		return err
+	}
+
+	sel.Having = ex
+	return nil
+}
+
+// validateHaving returns an error if the role is not allowed to use
+// functions or the columns the functions of the expression are applied to
+func validateHaving(qc *QCode, ex *Exp, tr trval) error {
+	if ex.Func != "" {
+		if tr.isFuncsBlocked() {
+			return blockedErr("functions blocked: %s_%s (%s)", ex.Func, ex.Col.Name, tr.role)
+		}
+		if !tr.columnAllowed(qc, ex.Col.Name) {
+			return blockedErr("column blocked: %s (%s)", ex.Col.Name, tr.role)
+		}
+	}
+
+	for _, c := range ex.Children {
+		if err := validateHaving(qc, c, tr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setHavingCol sets the column and the aggregate
+// function of the having expression
+func (ast *aexpst) setHavingCol(ex *Exp, node *graph.Node) error {
+	var list []string
+
+	for n := node.Parent; n != nil; n = n.Parent {
+		if n.Type != graph.NodeObj {
+			continue
+		}
+		switch n.Name {
+		case "", "and", "or", "not", "_and", "_or", "_not":
+			continue
+		}
+		list = append([]string{n.Name}, list...)
+	}
+
+	var name string
+
+	switch len(list) {
+	case 0:
+		name = node.Name
+	case 1:
+		name = list[0]
+	default:
+		return fmt.Errorf("related tables not supported: %s", list[0])
+	}
+
+	// database functions are not aggregates
+	n := aggPrefixLen(name)
+
+	if n == 0 {
+		return fmt.Errorf("'%s' is not an aggregate function", name)
+	}
+
+	col, err := ast.ti.GetColumn(name[n:])
+	if err != nil {
+		return err
+	}
+
+	ex.Col = col
+	ex.Func = name[:(n - 1)]
+
+	// used to type the values of variables
+	if ex.Func == "count" {
+		ex.Col.Type = "bigint"
+	}
+	return nil
+}
+
+// validateGroupBy returns an error if a column of the selector
+// is neither grouped nor used in an aggregate function
+func validateGroupBy(sel *Select) error {
+	if len(sel.GroupBy) == 0 {
+		return nil
+	}
+
+	for _, c := range sel.BCols {
+		if !isGrouped(sel, c.Col) {
+			return fmt.Errorf("group_by: column '%s' must be grouped or used in an aggregate function",
+				c.Col.Name)
+		}
+	}
+	return nil
+}
+
+func isGrouped(sel *Select, col sdata.DBColumn) bool {
+	for _, gb := range sel.GroupBy {
+		if gb.Col.Name == col.Name {
+			return true
+		}
+	}
+	return false
+}
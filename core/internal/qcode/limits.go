@@ -0,0 +1,99 @@
+package qcode
+
+import (
+	"fmt"
+)
+
+// LimitsConfig defines the limits enforced on queries made by a role,
+// a value of zero disables a limit.
+type LimitsConfig struct {
+	// MaxDepth is the max nesting depth of selectors
+	MaxDepth int
+
+	// MaxSelectors is the max number of selectors
+	// in the query (defaults to 30)
+	MaxSelectors int
+
+	// MaxRows is the max number of rows the query can return
+	// this is computed using the limit of each selector multiplied
+	// by the limits of its parents
+	MaxRows int
+
+	// MaxCost is the max cost of the query, each selector costs
+	// one for every time it's executed (once per row of its parent)
+	MaxCost int
+}
+
+// SetRoleLimits sets the limits enforced on queries made by the role
+func (co *Compiler) SetRoleLimits(role string, lc LimitsConfig) {
+	co.rl[role] = lc
+}
+
+func (co *Compiler) selectorLimit(role string) int32 {
+	if lc, ok := co.rl[role]; ok && lc.MaxSelectors != 0 {
+		return int32(lc.MaxSelectors)
+	}
+	return maxSelectors
+}
+
+// checkLimits checks the compiled selectors against the depth, rows and
+// cost limits of the role
+func (co *Compiler) checkLimits(qc *QCode, role string) error {
+	lc, ok := co.rl[role]
+	if !ok || (lc.MaxDepth == 0 && lc.MaxRows == 0 && lc.MaxCost == 0) {
+		return nil
+	}
+
+	sel := qc.Selects
+	depth := make([]int, len(sel))
+	rows := make([]int64, len(sel))
+
+	var totalRows, cost int64
+
+	// parents are always placed before their children
+	for i := range sel {
+		s := &sel[i]
+		execs := int64(1)
+
+		if s.ParentID == -1 {
+			depth[i] = 1
+		} else {
+			depth[i] = depth[s.ParentID] + 1
+			execs = rows[s.ParentID]
+		}
+
+		if lc.MaxDepth != 0 && depth[i] > lc.MaxDepth {
+			return fmt.Errorf("query depth limit reached (%d): %s", lc.MaxDepth, s.FieldName)
+		}
+
+		n := int64(1)
+		if !s.Singular && s.Paging.Limit > 0 {
+			n = int64(s.Paging.Limit)
+		}
+
+		rows[i] = capInt64(execs * n)
+		totalRows = capInt64(totalRows + rows[i])
+		cost = capInt64(cost + execs)
+
+		if lc.MaxRows != 0 && totalRows > int64(lc.MaxRows) {
+			return fmt.Errorf("query rows limit reached (%d): %s", lc.MaxRows, s.FieldName)
+		}
+
+		if lc.MaxCost != 0 && cost > int64(lc.MaxCost) {
+			return fmt.Errorf("query cost limit reached (%d): %s", lc.MaxCost, s.FieldName)
+		}
+	}
+
+	return nil
+}
+
+// capInt64 prevents overflows when multiplying the limits of
+// deeply nested selectors
+func capInt64(n int64) int64 {
+	const max = 1 << 40
+
+	if n > max || n < 0 {
+		return max
+	}
+	return n
+}
@@ -496,7 +496,11 @@ func getColumnsFromJSON(m *Mutate, trv trval, cm map[string]struct{}) ([]MColumn
 		}
 
 		if m.Ti.Blocked {
-			return nil, fmt.Errorf("column blocked: %s", k)
+			return nil, blockedErr("column blocked: %s", k)
+		}
+
+		if col.SQL != "" {
+			return nil, fmt.Errorf("column '%s' is computed and cannot be set", k)
 		}
 
 		cols = append(cols, MColumn{Col: m.Ti.Columns[i], FieldName: k})
@@ -18,6 +18,18 @@ const (
 	maxSelectors = 30
 )
 
+// ErrBlocked is matched (using errors.Is) by errors returned when the
+// role is not allowed to access a table, column or function.
+var ErrBlocked = errors.New("blocked")
+
+// Error is returned when compiling a selector fails. It carries the path
+// to the selector and its location in the query.
+type Error struct {
+	Path []string
+	Loc  graph.Location
+	err  error
+}
+
 type QType int8
 
 const (
@@ -64,6 +76,9 @@ type QCode struct {
 	MUnions   map[string][]int32
 	Schema    *sdata.DBSchema
 	Remotes   int32
+
+	// RootFields are root fields resolved outside the database
+	RootFields []RootField
 }
 
 type Select struct {
@@ -81,15 +96,22 @@ type Select struct {
 	Where      Filter
 	OrderBy    []OrderBy
 	GroupCols  bool
+	GroupBy    []GroupBy
+	Having     *Exp
 	DistinctOn []sdata.DBColumn
 	Paging     Paging
 	Children   []int32
+	Fields     []Field
 	SkipRender SkipType
 	Ti         sdata.DBTable
 	Rel        sdata.DBRel
 	Joins      []sdata.DBRel
 	order      Order
 	through    string
+
+	// TableFunc is set on root selectors that read the rows
+	// from a function instead of the table
+	TableFunc *TableFunc
 }
 
 type Column struct {
@@ -120,6 +142,10 @@ type Exp struct {
 	Children  []*Exp
 	childrenA [5]*Exp
 	Path      []string
+
+	// Func is set on having expressions to the aggregate
+	// function applied to the column eg. count
+	Func string
 }
 
 type Arg struct {
@@ -225,6 +251,10 @@ type Compiler struct {
 	c  Config
 	s  *sdata.DBSchema
 	tr map[string]trval
+	rl map[string]LimitsConfig
+	rf map[string]rootFieldConf
+	tm map[string]string
+	pr map[string]map[string]struct{}
 }
 
 func NewCompiler(s *sdata.DBSchema, c Config) (*Compiler, error) {
@@ -238,22 +268,85 @@ func NewCompiler(s *sdata.DBSchema, c Config) (*Compiler, error) {
 	c.defTrv.upsert.block = c.DefaultBlock
 	c.defTrv.delete.block = c.DefaultBlock
 
-	return &Compiler{c: c, s: s, tr: make(map[string]trval)}, nil
+	return &Compiler{
+		c:  c,
+		s:  s,
+		tr: make(map[string]trval),
+		rl: make(map[string]LimitsConfig),
+		rf: make(map[string]rootFieldConf),
+		tm: newTypeMap(c.TypeMap),
+		pr: make(map[string]map[string]struct{}),
+	}, nil
 }
 
 type Variables map[string]json.RawMessage
 
+// Compile compiles the first operation in the query
 func (co *Compiler) Compile(query []byte, vars Variables, role string) (*QCode, error) {
-	var err error
+	return co.CompileOp(query, "", vars, role)
+}
 
-	qc := QCode{SType: QTQuery, Schema: co.s, Vars: vars}
-	qc.Roots = qc.rootsA[:0]
+// CompileOp compiles the operation named opName in the query, this is
+// used when a query document contains multiple operations.
+func (co *Compiler) CompileOp(query []byte, opName string, vars Variables, role string) (*QCode, error) {
+	op, err := graph.Parse(query, opName, co.c.FragmentFetcher)
+	if err != nil {
+		return nil, err
+	}
+
+	if op.Type == graph.OpMutate && len(co.rootSelectors(&op)) > 1 {
+		return nil, errors.New("multiple root mutations: use CompileRoots")
+	}
+
+	return co.compileOp(&op, -1, vars, role)
+}
+
+// CompileRoots compiles each root field of the operation named opName into its
+// own QCode. It's used for mutations with more than one root field since each of
+// them needs its own set of statements.
+func (co *Compiler) CompileRoots(query []byte, opName string, vars Variables, role string) ([]*QCode, error) {
+	op, err := graph.Parse(query, opName, co.c.FragmentFetcher)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := co.rootSelectors(&op)
+
+	// only root fields resolved outside the database
+	if len(roots) == 0 {
+		qc, err := co.compileOp(&op, -1, vars, role)
+		if err != nil {
+			return nil, err
+		}
+		return []*QCode{qc}, nil
+	}
+
+	qcs := make([]*QCode, 0, len(roots))
 
-	op, err := graph.Parse(query, co.c.FragmentFetcher)
+	for _, id := range roots {
+		qc, err := co.compileOp(&op, id, vars, role)
+		if err != nil {
+			return nil, err
+		}
+		qcs = append(qcs, qc)
+	}
+
+	// the root fields resolved outside the database
+	// are only added to the first one
+	qcs[0].RootFields, err = co.compileRootFields(&op, role)
 	if err != nil {
 		return nil, err
 	}
 
+	return qcs, nil
+}
+
+// compileOp compiles the operation, if root is not -1 then only the root
+// field with that id and it's children are compiled.
+func (co *Compiler) compileOp(op *graph.Operation, root int32, vars Variables, role string) (*QCode, error) {
+	qc := QCode{SType: QTQuery, Schema: co.s, Vars: vars}
+	qc.Roots = qc.rootsA[:0]
+
 	switch op.Type {
 	case graph.OpQuery:
 		qc.Type = QTQuery
@@ -265,12 +358,24 @@ func (co *Compiler) Compile(query []byte, vars Variables, role string) (*QCode,
 		return nil, fmt.Errorf("invalid operation: %s", op.Type)
 	}
 
-	if err := co.compileQuery(&qc, &op, role); err != nil {
+	if err := co.compileQuery(&qc, op, root, role); err != nil {
 		return nil, err
 	}
 
-	if qc.Type == QTMutation {
-		if err = co.compileMutation(&qc, &op, role); err != nil {
+	if root == -1 {
+		var err error
+		if qc.RootFields, err = co.compileRootFields(op, role); err != nil {
+			return nil, err
+		}
+
+		if len(qc.Selects) == 0 && len(qc.RootFields) == 0 {
+			return nil, errors.New("invalid graphql no query found")
+		}
+	}
+
+	// functions called by mutations are selected from
+	if qc.Type == QTMutation && len(qc.Selects) != 0 && qc.Selects[0].TableFunc == nil {
+		if err := co.compileMutation(&qc, op, role); err != nil {
 			return nil, err
 		}
 	}
@@ -278,7 +383,30 @@ func (co *Compiler) Compile(query []byte, vars Variables, role string) (*QCode,
 	return &qc, nil
 }
 
-func (co *Compiler) compileQuery(qc *QCode, op *graph.Operation, role string) error {
+// rootSelectors returns the ids of the root fields of the operation
+// selected from the database, skipping keyword fields like cursors
+// and the root fields resolved outside the database (see isRootField)
+func (co *Compiler) rootSelectors(op *graph.Operation) []int32 {
+	var ids []int32
+
+	for _, f := range op.Fields {
+		if f.ParentID == -1 && !co.isKeyword(op, f) && !co.isRootField(op, f) {
+			ids = append(ids, f.ID)
+		}
+	}
+	return ids
+}
+
+// isKeyword returns true for fields without arguments or children at the
+// top-level, except for calls of functions without arguments in mutations
+func (co *Compiler) isKeyword(op *graph.Operation, f graph.Field) bool {
+	if f.Type != graph.FieldKeyword {
+		return false
+	}
+	return op.Type != graph.OpMutate || !co.IsProcedure(f.Name)
+}
+
+func (co *Compiler) compileQuery(qc *QCode, op *graph.Operation, root int32, role string) error {
 	var id int32
 
 	if len(op.Fields) == 0 {
@@ -286,20 +414,32 @@ func (co *Compiler) compileQuery(qc *QCode, op *graph.Operation, role string) er
 	}
 
 	if op.Type == graph.OpMutate {
-		if err := co.setMutationType(qc, op.Fields[0].Args); err != nil {
-			return err
+		mid := root
+		if mid == -1 {
+			if ids := co.rootSelectors(op); len(ids) != 0 {
+				mid = ids[0]
+			}
+		}
+		if mid != -1 {
+			if err := co.setMutationType(qc, op.Fields[mid].Args); err != nil {
+				return err
+			}
 		}
 	}
 
 	qc.Selects = make([]Select, 0, 5)
 	st := util.NewStackInt32()
+	maxSel := co.selectorLimit(role)
 
 	if len(op.Fields) == 0 {
 		return errors.New("empty query")
 	}
 
 	for _, f := range op.Fields {
-		if f.ParentID == -1 {
+		if co.isRootField(op, f) {
+			continue
+		}
+		if f.ParentID == -1 && (root == -1 || f.ID == root) {
 			val := f.ID | (-1 << 16)
 			st.Push(val)
 		}
@@ -310,8 +450,8 @@ func (co *Compiler) compileQuery(qc *QCode, op *graph.Operation, role string) er
 			break
 		}
 
-		if id >= maxSelectors {
-			return fmt.Errorf("selector limit reached (%d)", maxSelectors)
+		if id >= maxSel {
+			return fmt.Errorf("selector limit reached (%d)", maxSel)
 		}
 
 		val := st.Pop()
@@ -322,7 +462,7 @@ func (co *Compiler) compileQuery(qc *QCode, op *graph.Operation, role string) er
 
 		// A keyword is a cursor field at the top-level
 		// For example posts_cursor in the root
-		if field.Type == graph.FieldKeyword {
+		if co.isKeyword(op, field) {
 			continue
 		}
 
@@ -345,31 +485,41 @@ func (co *Compiler) compileQuery(qc *QCode, op *graph.Operation, role string) er
 		sel.Children = make([]int32, 0, 5)
 
 		if err := co.compileDirectives(qc, sel, field.Directives); err != nil {
-			return err
+			return selectErr(qc, sel, field, err)
 		}
 
 		if err := co.addRelInfo(op, qc, sel, field); err != nil {
-			return err
+			return selectErr(qc, sel, field, err)
 		}
 
-		tr := co.getRole(role, field.Name)
+		// functions use the role config of the table they return
+		tname := field.Name
+		if sel.TableFunc != nil {
+			tname = sel.Table
+		}
+
+		tr := co.getRole(role, tname)
 
 		if tr.isSkipped(qc.Type) {
 			sel.SkipRender = SkipTypeUserNeeded
 		} else {
-			if err := tr.isBlocked(qc.SType, field.Name); err != nil {
-				return err
+			if err := tr.isBlocked(qc.SType, tname); err != nil {
+				return selectErr(qc, sel, field, err)
 			}
 		}
 
+		if err := co.isCallBlocked(qc, sel, role); err != nil {
+			return selectErr(qc, sel, field, err)
+		}
+
 		co.setLimit(tr, qc, sel)
 
-		if err := co.compileArgs(qc, sel, field.Args, role); err != nil {
-			return err
+		if err := co.compileArgs(qc, sel, field.Args, tr); err != nil {
+			return selectErr(qc, sel, field, err)
 		}
 
 		if err := co.compileColumns(st, op, qc, sel, field, tr); err != nil {
-			return err
+			return selectErr(qc, sel, field, err)
 		}
 
 		// Order is important AddFilters must come after compileArgs
@@ -382,7 +532,7 @@ func (co *Compiler) compileQuery(qc *QCode, op *graph.Operation, role string) er
 			// Set tie-breaker order column for the cursor direction
 			// this column needs to be the last in the order series.
 			if err := co.orderByIDCol(sel); err != nil {
-				return err
+				return selectErr(qc, sel, field, err)
 			}
 
 			// Set filter chain needed to make the cursor work
@@ -392,18 +542,20 @@ func (co *Compiler) compileQuery(qc *QCode, op *graph.Operation, role string) er
 		}
 
 		if err := co.validateSelect(sel); err != nil {
-			return err
+			return selectErr(qc, sel, field, err)
 		}
 
 		qc.Selects = append(qc.Selects, s1)
 		id++
 	}
 
-	if id == 0 {
+	// a query can be made up of only root fields resolved
+	// outside the database
+	if id == 0 && len(co.rootSelectors(op)) != 0 {
 		return errors.New("invalid query")
 	}
 
-	return nil
+	return co.checkLimits(qc, role)
 }
 
 func (co *Compiler) addRelInfo(
@@ -447,7 +599,12 @@ func (co *Compiler) addRelInfo(
 	}
 
 	if sel.ParentID != -1 {
-		paths, err := co.s.FindPath(childF.Name, parentF.Name)
+		pname := parentF.Name
+		if psel.TableFunc != nil && field.Type != graph.FieldMember {
+			pname = psel.Table
+		}
+
+		paths, err := co.s.FindPath(childF.Name, pname)
 		if err != nil {
 			return err
 		}
@@ -463,7 +620,22 @@ func (co *Compiler) addRelInfo(
 
 	if sel.ParentID == -1 || sel.Rel.Type == sdata.RelPolymorphic {
 		schema := co.c.DBSchema
-		if sel.Ti, err = co.s.Find(schema, field.Name); err != nil {
+		fn, isFunc := co.s.GetTableFunction(field.Name)
+		isProc := co.IsProcedure(field.Name)
+
+		switch {
+		case isProc && sel.ParentID == -1 && qc.Type == QTMutation:
+			err = co.setProcedure(sel, field.Name)
+
+		// functions called by mutations are not queried
+		case isFunc && !isProc && sel.ParentID == -1 && qc.Type != QTMutation:
+			sel.Ti, err = co.s.Find(fn.ResultTable())
+			sel.TableFunc = &TableFunc{Name: fn.Name, params: fn.Params}
+
+		default:
+			sel.Ti, err = co.s.Find(schema, field.Name)
+		}
+		if err != nil {
 			return err
 		}
 	} else {
@@ -471,7 +643,7 @@ func (co *Compiler) addRelInfo(
 	}
 
 	if sel.Ti.Blocked {
-		return fmt.Errorf("table: '%t' (%s) blocked", sel.Ti.Blocked, field.Name)
+		return blockedErr("table: '%t' (%s) blocked", sel.Ti.Blocked, field.Name)
 	}
 
 	sel.Table = sel.Ti.Name
@@ -631,7 +803,7 @@ func (co *Compiler) compileDirectives(qc *QCode, sel *Select, dirs []graph.Direc
 	return nil
 }
 
-func (co *Compiler) compileArgs(qc *QCode, sel *Select, args []graph.Arg, role string) error {
+func (co *Compiler) compileArgs(qc *QCode, sel *Select, args []graph.Arg, tr trval) error {
 	var err error
 
 	for i := range args {
@@ -645,7 +817,7 @@ func (co *Compiler) compileArgs(qc *QCode, sel *Select, args []graph.Arg, role s
 			err = co.compileArgSearch(sel, arg)
 
 		case "where":
-			err = co.compileArgWhere(sel.Ti, sel, arg, role)
+			err = co.compileArgWhere(sel.Ti, sel, arg, tr.role)
 
 		case "orderby", "order_by", "order":
 			err = co.compileArgOrderBy(sel, arg)
@@ -673,6 +845,17 @@ func (co *Compiler) compileArgs(qc *QCode, sel *Select, args []graph.Arg, role s
 
 		case "find":
 			err = co.compileArgFind(sel, arg)
+
+		case "group_by":
+			err = co.compileArgGroupBy(sel, arg)
+
+		case "having":
+			err = co.compileArgHaving(qc, sel, arg, tr)
+
+		default:
+			if sel.TableFunc != nil {
+				err = co.compileArgTableFunc(sel, arg)
+			}
 		}
 
 		if err != nil {
@@ -684,6 +867,12 @@ func (co *Compiler) compileArgs(qc *QCode, sel *Select, args []graph.Arg, role s
 }
 
 func (co *Compiler) validateSelect(sel *Select) error {
+	if sel.TableFunc != nil {
+		if err := sel.TableFunc.validateArgs(); err != nil {
+			return err
+		}
+	}
+
 	if sel.Rel.Type == sdata.RelRecursive {
 		v, ok := sel.ArgMap["find"]
 		if !ok {
@@ -693,7 +882,7 @@ func (co *Compiler) validateSelect(sel *Select) error {
 			return fmt.Errorf("find: valid values are 'parents' and 'children'")
 		}
 	}
-	return nil
+	return validateGroupBy(sel)
 }
 
 func (co *Compiler) setMutationType(qc *QCode, args []graph.Arg) error {
@@ -1227,6 +1416,49 @@ func (t ExpOp) String() string {
 	return fmt.Sprintf("<%s>", v)
 }
 
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// selectErr wraps err with the path of the selector sel built by walking
+// up its parent chain.
+func selectErr(qc *QCode, sel *Select, field graph.Field, err error) error {
+	n := 1
+	for i := sel.ParentID; i != -1; i = qc.Selects[i].ParentID {
+		n++
+	}
+	path := make([]string, n)
+	path[n-1] = sel.FieldName
+
+	n--
+	for i := sel.ParentID; i != -1; i = qc.Selects[i].ParentID {
+		n--
+		path[n] = qc.Selects[i].FieldName
+	}
+
+	return &Error{Path: path, Loc: field.Loc, err: err}
+}
+
+type blockedError struct {
+	msg string
+}
+
+func (e blockedError) Error() string {
+	return e.msg
+}
+
+func (e blockedError) Is(target error) bool {
+	return target == ErrBlocked
+}
+
+func blockedErr(format string, a ...interface{}) error {
+	return blockedError{msg: fmt.Sprintf(format, a...)}
+}
+
 func argErr(name, ty string) error {
 	return fmt.Errorf("value for argument '%s' must be a %s", name, ty)
 }
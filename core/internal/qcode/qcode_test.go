@@ -92,6 +92,528 @@ func TestCompile3(t *testing.T) {
 	}
 }
 
+func TestCompileErrorPath(t *testing.T) {
+	qc, _ := qcode.NewCompiler(dbs, qcode.Config{})
+	err := qc.AddRole("user", "public", "users", qcode.TRConfig{
+		Query: qcode.QueryConfig{
+			Columns: []string{"id"},
+		},
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	_, err = qc.Compile([]byte(`
+	query {
+		products {
+			id
+			users {
+				email
+			}
+		}
+	}`), nil, "user")
+
+	if !errors.Is(err, qcode.ErrBlocked) {
+		t.Fatalf("expected a blocked error, got: %v", err)
+	}
+
+	var qe *qcode.Error
+	if !errors.As(err, &qe) {
+		t.Fatal(errors.New("expected a qcode.Error"))
+	}
+
+	if len(qe.Path) != 2 || qe.Path[0] != "products" || qe.Path[1] != "users" {
+		t.Fatalf("unexpected path: %v", qe.Path)
+	}
+
+	if qe.Loc.Line != 5 || qe.Loc.Column != 4 {
+		t.Fatalf("unexpected location: %+v", qe.Loc)
+	}
+}
+
+func TestCompileRoots(t *testing.T) {
+	gql := `mutation {
+		products(insert: $product) {
+			id
+		}
+		users(update: $user, where: { id: { eq: 1 } }) {
+			id
+			email
+		}
+	}`
+
+	vars := map[string]json.RawMessage{
+		"product": json.RawMessage(`{ "name": "Product 1", "price": 10 }`),
+		"user":    json.RawMessage(`{ "email": "user1@test.com" }`),
+	}
+
+	qcompile, _ := qcode.NewCompiler(dbs, qcode.Config{})
+
+	if _, err := qcompile.Compile([]byte(gql), vars, "user"); err == nil {
+		t.Fatal(errors.New("expecting an error"))
+	}
+
+	qcs, err := qcompile.CompileRoots([]byte(gql), "", vars, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(qcs) != 2 {
+		t.Fatalf("expected 2 root mutations got %d", len(qcs))
+	}
+
+	if qcs[0].SType != qcode.QTInsert || qcs[0].Selects[0].Table != "products" {
+		t.Fatalf("expected an insert on products")
+	}
+
+	if qcs[1].SType != qcode.QTUpdate || qcs[1].Selects[0].Table != "users" {
+		t.Fatalf("expected an update on users")
+	}
+}
+
+func TestCompileRootFields(t *testing.T) {
+	gql := `query {
+		products {
+			id
+		}
+		w: weather(city: $city, days: 3) {
+			temp
+		}
+	}`
+
+	vars := map[string]json.RawMessage{
+		"city": json.RawMessage(`"Paris"`),
+	}
+
+	qcompile, _ := qcode.NewCompiler(dbs, qcode.Config{})
+	qcompile.AddRootField("weather", qcode.QTQuery, nil)
+
+	qc, err := qcompile.Compile([]byte(gql), vars, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(qc.Selects) != 1 || qc.Selects[0].Table != "products" {
+		t.Fatalf("expected a single select on products")
+	}
+
+	if len(qc.RootFields) != 1 {
+		t.Fatalf("expected 1 root field got %d", len(qc.RootFields))
+	}
+
+	rf := qc.RootFields[0]
+
+	if rf.Name != "weather" || rf.FieldName != "w" {
+		t.Fatalf("unexpected root field: %s (%s)", rf.Name, rf.FieldName)
+	}
+
+	if args := rf.Args(vars); string(args) != `{"city":"Paris","days":3}` {
+		t.Fatalf("unexpected args: %s", args)
+	}
+
+	if len(rf.Fields) != 1 || rf.Fields[0].Name != "temp" {
+		t.Fatalf("expected the temp field to be selected")
+	}
+
+	// a mutation with only a root field resolved outside the database
+	qcompile.AddRootField("send_email", qcode.QTMutation, []string{"user"})
+
+	qcs, err := qcompile.CompileRoots([]byte(`mutation { send_email(to: "a@b.com") }`), "", nil, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(qcs) != 1 || len(qcs[0].Selects) != 0 || len(qcs[0].RootFields) != 1 {
+		t.Fatalf("expected a single root field and no selects")
+	}
+
+	// only the user role can use the field
+	_, err = qcompile.CompileRoots([]byte(`mutation { send_email(to: "a@b.com") }`), "", nil, "anon")
+	if !errors.Is(err, qcode.ErrBlocked) {
+		t.Fatalf("expected the root field to be blocked for anon: %v", err)
+	}
+}
+
+func TestCompileRoleLimits(t *testing.T) {
+	// a product has one owner and a user many products
+	// rows: 10 + (10 * 1) + (10 * 10) = 120
+	// cost: 1 + 10 + 10 = 21
+	gql := `query {
+		products(limit: 10) {
+			id
+			users(limit: 10) {
+				id
+				products(limit: 10) {
+					id
+				}
+			}
+		}
+	}`
+
+	tests := []struct {
+		lc  qcode.LimitsConfig
+		err bool
+	}{
+		{qcode.LimitsConfig{}, false},
+		{qcode.LimitsConfig{MaxDepth: 3}, false},
+		{qcode.LimitsConfig{MaxDepth: 2}, true},
+		{qcode.LimitsConfig{MaxSelectors: 2}, true},
+		{qcode.LimitsConfig{MaxRows: 120}, false},
+		{qcode.LimitsConfig{MaxRows: 119}, true},
+		{qcode.LimitsConfig{MaxCost: 21}, false},
+		{qcode.LimitsConfig{MaxCost: 20}, true},
+	}
+
+	for i, v := range tests {
+		qcompile, _ := qcode.NewCompiler(dbs, qcode.Config{})
+		qcompile.SetRoleLimits("user", v.lc)
+
+		_, err := qcompile.Compile([]byte(gql), nil, "user")
+		if v.err && err == nil {
+			t.Fatalf("%d: expecting an error", i)
+		}
+		if !v.err && err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+	}
+}
+
+func TestCompileEnumValues(t *testing.T) {
+	tests := []struct {
+		gql  string
+		vars string
+		err  bool
+	}{
+		{`query { purchases(where: { sale_type: { eq: "bought" } }) { id } }`, ``, false},
+		{`query { purchases(where: { sale_type: { eq: "sold" } }) { id } }`, ``, true},
+		{`query { purchases(where: { sale_type: { in: ["rented", "sold"] } }) { id } }`, ``, true},
+		{`query { purchases(where: { sale_type: { like: "sol%" } }) { id } }`, ``, false},
+		{`query { purchases(where: { sale_type: { eq: $type } }) { id } }`, `{ "type": "rented" }`, false},
+		{`query { purchases(where: { sale_type: { eq: $type } }) { id } }`, `{ "type": "sold" }`, true},
+		{`query { purchases(where: { sale_type: { in: $types } }) { id } }`, `{ "types": ["rented", "sold"] }`, true},
+		{`mutation { purchases(insert: $data) { id } }`, `{ "data": { "sale_type": "bought", "quantity": 1 } }`, false},
+		{`mutation { purchases(insert: $data) { id } }`, `{ "data": [{ "sale_type": "bought" }, { "sale_type": "sold" }] }`, true},
+	}
+
+	qcompile, _ := qcode.NewCompiler(dbs, qcode.Config{})
+
+	for i, v := range tests {
+		var vars qcode.Variables
+
+		if v.vars != "" {
+			if err := json.Unmarshal([]byte(v.vars), &vars); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		qc, err := qcompile.Compile([]byte(v.gql), vars, "user")
+		if err == nil {
+			err = qcompile.ValidateVars(qc, vars)
+		}
+
+		if v.err && err == nil {
+			t.Fatalf("%d: expecting an error", i)
+		}
+		if !v.err && err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+	}
+}
+
+func TestCompileScalarValues(t *testing.T) {
+	tests := []struct {
+		gql  string
+		vars string
+		err  bool
+	}{
+		{`query { users(where: { created_at: { gt: $date } }) { id } }`, `{ "date": "2021-01-09T16:37:01Z" }`, false},
+		{`query { users(where: { created_at: { gt: $date } }) { id } }`, `{ "date": "2021-01-09 16:37:01" }`, false},
+		{`query { users(where: { created_at: { gt: $date } }) { id } }`, `{ "date": "yesterday" }`, false},
+		{`query { users(where: { created_at: { gt: $date } }) { id } }`, `{ "date": "09/01/2021" }`, true},
+		{`query { users(where: { id: { in: $ids } }) { id } }`, `{ "ids": [1, "2"] }`, false},
+		{`query { users(where: { id: { in: $ids } }) { id } }`, `{ "ids": [1, 2.5] }`, true},
+		{`query { products(where: { price: { gt: $price } }) { id } }`, `{ "price": "10.50" }`, false},
+		{`query { products(where: { price: { gt: $price } }) { id } }`, `{ "price": "ten" }`, true},
+		{`query { users(where: { full_name: { eq: $name } }) { id } }`, `{ "name": 10 }`, false},
+		{`mutation { purchases(insert: $data) { id } }`, `{ "data": { "quantity": 1, "due_date": "now" } }`, false},
+		{`mutation { purchases(insert: $data) { id } }`, `{ "data": { "quantity": "one" } }`, true},
+		{`mutation { users(update: $data, id: 1) { id } }`, `{ "data": { "updated_at": 1610210221 } }`, true},
+	}
+
+	qcompile, _ := qcode.NewCompiler(dbs, qcode.Config{})
+
+	for i, v := range tests {
+		var vars qcode.Variables
+
+		if err := json.Unmarshal([]byte(v.vars), &vars); err != nil {
+			t.Fatal(err)
+		}
+
+		qc, err := qcompile.Compile([]byte(v.gql), vars, "user")
+		if err == nil {
+			err = qcompile.ValidateVars(qc, vars)
+		}
+
+		if v.err && err == nil {
+			t.Fatalf("%d: expecting an error", i)
+		}
+		if !v.err && err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+	}
+}
+
+func TestCompileTypeMap(t *testing.T) {
+	qcompile, _ := qcode.NewCompiler(dbs, qcode.Config{
+		TypeMap: map[string]string{"Character Varying": "UUID"},
+	})
+
+	vars := qcode.Variables{"id": json.RawMessage(`"not-a-uuid"`)}
+
+	qc, err := qcompile.Compile([]byte(`query { users(where: { phone: { eq: $id } }) { id } }`), vars, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := qcompile.ValidateVars(qc, vars); err == nil {
+		t.Fatal(errors.New("expecting an error"))
+	}
+
+	vars["id"] = json.RawMessage(`"a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"`)
+
+	if err := qcompile.ValidateVars(qc, vars); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompileComputedColumns(t *testing.T) {
+	qcompile, _ := qcode.NewCompiler(dbs, qcode.Config{})
+
+	err := qcompile.AddRole("anon", "public", "users", qcode.TRConfig{
+		Query: qcode.QueryConfig{Columns: []string{"id", "full_name"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gql := `query {
+		users(where: { display_name: { ilike: "%@example.com>" } }, order_by: { display_name: asc }) {
+			id
+			display_name
+		}
+	}`
+
+	qc, err := qcompile.Compile([]byte(gql), nil, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if qc.Selects[0].Cols[1].Col.SQL == "" {
+		t.Fatal(errors.New("expecting a computed column"))
+	}
+
+	if _, err := qcompile.Compile([]byte(gql), nil, "anon"); err == nil {
+		t.Fatal(errors.New("expecting an error: column not in role"))
+	}
+
+	vars := qcode.Variables{"data": json.RawMessage(`{ "display_name": "Jane" }`)}
+
+	_, err = qcompile.Compile([]byte(`mutation { users(insert: $data) { id } }`), vars, "user")
+	if err == nil {
+		t.Fatal(errors.New("expecting an error: computed column set"))
+	}
+}
+
+func TestCompileTableFunctions(t *testing.T) {
+	qcompile, _ := qcode.NewCompiler(dbs, qcode.Config{})
+
+	gql := `query {
+		search_products(max_price: 20, term: $term, where: { id: { gt: 2 } }, limit: 5) {
+			id
+			name
+			users {
+				id
+			}
+		}
+	}`
+
+	qc, err := qcompile.Compile([]byte(gql), nil, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sel := qc.Selects[0]
+
+	if sel.TableFunc == nil || sel.Table != "products" {
+		t.Fatal(errors.New("expecting a function returning products"))
+	}
+
+	if args := sel.TableFunc.Args; len(args) != 2 ||
+		args[0].Name != "term" || args[1].Name != "max_price" {
+		t.Fatal(errors.New("expecting the arguments sorted by position"))
+	}
+
+	if _, err := qcompile.Compile([]byte(`query { top_buyers(since: "2020-01-01") { user_id total } }`), nil, "user"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = qcompile.Compile([]byte(`query { search_products(text: "x") { id } }`), nil, "user")
+	if err == nil {
+		t.Fatal(errors.New("expecting an error: invalid argument"))
+	}
+
+	_, err = qcompile.Compile([]byte(`query { search_products(max_price: 20) { id } }`), nil, "user")
+	if err == nil {
+		t.Fatal(errors.New("expecting an error: missing argument"))
+	}
+}
+
+func TestCompileProcedures(t *testing.T) {
+	qcompile, _ := qcode.NewCompiler(dbs, qcode.Config{})
+
+	if err := qcompile.AddProcedure("set_price", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := qcompile.AddProcedure("close_account", []string{"admin"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := qcompile.AddProcedure("drop_tables", nil); err == nil {
+		t.Fatal(errors.New("expecting an error: function not found"))
+	}
+
+	gql := `mutation {
+		set_price(product_id: $id, new_price: $price) {
+			id
+			price
+		}
+	}`
+
+	vars := qcode.Variables{"id": json.RawMessage(`5`), "price": json.RawMessage(`"abc"`)}
+
+	qc, err := qcompile.Compile([]byte(gql), vars, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sel := qc.Selects[0]
+
+	if sel.TableFunc == nil || sel.Table != "products" || !sel.Singular || len(qc.Mutates) != 0 {
+		t.Fatal(errors.New("expecting a function returning a product"))
+	}
+
+	if err := qcompile.ValidateVars(qc, vars); err == nil {
+		t.Fatal(errors.New("expecting an error: invalid argument value"))
+	}
+
+	gql = `mutation { close_account(user_id: $id) }`
+
+	if _, err := qcompile.Compile([]byte(gql), nil, "admin"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := qcompile.Compile([]byte(gql), nil, "user"); err == nil {
+		t.Fatal(errors.New("expecting an error: role not allowed"))
+	}
+
+	gql = `mutation { set_price(product_id: 5, new_price: 10) { id } }`
+
+	if _, err := qcompile.Compile([]byte(gql), nil, "anon"); err == nil {
+		t.Fatal(errors.New("expecting an error: anon not allowed"))
+	}
+
+	if err := qcompile.AddProcedure("set_price", []string{"anon"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := qcompile.Compile([]byte(gql), nil, "anon"); err != nil {
+		t.Fatal(err)
+	}
+
+	gql = `mutation { close_account(user_id: $id) { id } }`
+
+	if _, err := qcompile.Compile([]byte(gql), nil, "admin"); err == nil {
+		t.Fatal(errors.New("expecting an error: selection on a function not returning rows"))
+	}
+}
+
+func TestCompileGroupBy(t *testing.T) {
+	qcompile, _ := qcode.NewCompiler(dbs, qcode.Config{})
+
+	gql := `query {
+		products(
+			group_by: [{ field: name }, { date_trunc: { field: created_at, unit: month } }],
+			having: { count_id: { gt: 5 } }) {
+			name
+			created_at
+			count_id
+		}
+	}`
+
+	qc, err := qcompile.Compile([]byte(gql), nil, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sel := qc.Selects[0]
+
+	if len(sel.GroupBy) != 2 || sel.GroupBy[0].Col.Name != "name" ||
+		sel.GroupBy[1].Col.Name != "created_at" || sel.GroupBy[1].Trunc != "month" {
+		t.Fatal(errors.New("expecting grouping by name and the month of created_at"))
+	}
+
+	if ex := sel.Having; ex == nil || ex.Func != "count" || ex.Col.Name != "id" {
+		t.Fatal(errors.New("expecting having on count(id)"))
+	}
+
+	fail := []string{
+		`query { products(group_by: { date_trunc: { field: created_at, unit: decade } }) { count_id } }`,
+		`query { products(group_by: { date_trunc: { field: name, unit: day } }) { count_id } }`,
+		`query { products(group_by: name) { name price count_id } }`,
+		`query { products(group_by: name, having: { price: { gt: 5 } }) { name count_id } }`,
+		`query { products(group_by: name, having: { lower_name: { eq: "a" } }) { name count_id } }`,
+	}
+
+	for _, gql := range fail {
+		if _, err := qcompile.Compile([]byte(gql), nil, "user"); err == nil {
+			t.Fatalf("expecting an error: %s", gql)
+		}
+	}
+}
+
+func TestCompileHavingRoles(t *testing.T) {
+	qcompile, _ := qcode.NewCompiler(dbs, qcode.Config{})
+
+	err := qcompile.AddRole("anon", "public", "products", qcode.TRConfig{
+		Query: qcode.QueryConfig{Columns: []string{"id", "name"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = qcompile.AddRole("anon1", "public", "products", qcode.TRConfig{
+		Query: qcode.QueryConfig{DisableFunctions: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gql := `query { products(group_by: name, having: { sum_price: { gt: 5 } }) { name } }`
+
+	if _, err := qcompile.Compile([]byte(gql), nil, "user"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := qcompile.Compile([]byte(gql), nil, "anon"); !errors.Is(err, qcode.ErrBlocked) {
+		t.Fatalf("expecting a column blocked error: %v", err)
+	}
+
+	if _, err := qcompile.Compile([]byte(gql), nil, "anon1"); !errors.Is(err, qcode.ErrBlocked) {
+		t.Fatalf("expecting a functions blocked error: %v", err)
+	}
+}
+
 func TestInvalidCompile1(t *testing.T) {
 	qcompile, _ := qcode.NewCompiler(dbs, qcode.Config{})
 	_, err := qcompile.Compile([]byte(`#`), nil, "user")
@@ -0,0 +1,106 @@
+package qcode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dosco/graphjin/core/internal/graph"
+)
+
+// Field struct is a field selected on a remote selector. Remote selectors
+// are not compiled so their fields are kept as is, along with the arguments
+// rendered as GraphQL, to be used by the remote resolver. Since compiled queries
+// are cached the arguments can't use variables.
+type Field struct {
+	Name     string
+	Alias    string
+	Args     string
+	Children []Field
+}
+
+func newRemoteField(op *graph.Operation, f graph.Field) (Field, error) {
+	rf := Field{Name: f.Name, Alias: f.Alias}
+
+	if len(f.Args) != 0 {
+		var sb strings.Builder
+
+		if err := renderArgs(&sb, f.Args); err != nil {
+			return rf, fmt.Errorf("remote field '%s': %w", f.Name, err)
+		}
+		rf.Args = sb.String()
+	}
+
+	for _, cid := range f.Children {
+		cf, err := newRemoteField(op, op.Fields[cid])
+		if err != nil {
+			return rf, err
+		}
+		rf.Children = append(rf.Children, cf)
+	}
+
+	return rf, nil
+}
+
+func renderArgs(sb *strings.Builder, args []graph.Arg) error {
+	for i, a := range args {
+		if i != 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(a.Name)
+		sb.WriteString(": ")
+
+		if err := renderValue(sb, a.Val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderValue(sb *strings.Builder, n *graph.Node) error {
+	switch n.Type {
+	case graph.NodeStr:
+		// enum values are not quoted
+		if n.Label {
+			sb.WriteString(n.Val)
+		} else {
+			sb.WriteString(strconv.Quote(n.Val))
+		}
+
+	case graph.NodeNum, graph.NodeBool:
+		sb.WriteString(n.Val)
+
+	case graph.NodeList:
+		sb.WriteByte('[')
+		for i, c := range n.Children {
+			if i != 0 {
+				sb.WriteString(", ")
+			}
+			if err := renderValue(sb, c); err != nil {
+				return err
+			}
+		}
+		sb.WriteByte(']')
+
+	case graph.NodeObj:
+		sb.WriteByte('{')
+		for i, c := range n.Children {
+			if i != 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(c.Name)
+			sb.WriteString(": ")
+			if err := renderValue(sb, c); err != nil {
+				return err
+			}
+		}
+		sb.WriteByte('}')
+
+	case graph.NodeVar:
+		return fmt.Errorf("variables are not supported: $%s", n.Val)
+
+	default:
+		return fmt.Errorf("unsupported argument value: %s", n.Val)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package qcode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dosco/graphjin/core/internal/graph"
+)
+
+// RootField struct is a root field of the query that's resolved outside the
+// database. Compiled queries are cached so the variables in the arguments are
+// only replaced by their values when Args is called.
+type RootField struct {
+	Name      string
+	FieldName string
+	Fields    []Field
+	args      []argPart
+}
+
+// argPart is either a literal part of the arguments JSON or a variable
+type argPart struct {
+	lit []byte
+	v   string
+}
+
+// Args returns the arguments of the field as a JSON object with the
+// variables replaced by their values
+func (rf *RootField) Args(vars Variables) json.RawMessage {
+	var b bytes.Buffer
+
+	for _, p := range rf.args {
+		if p.lit != nil {
+			b.Write(p.lit)
+		} else if v, ok := vars[p.v]; ok && len(v) != 0 {
+			b.Write(v)
+		} else {
+			b.WriteString("null")
+		}
+	}
+	return b.Bytes()
+}
+
+// rootFieldConf is the type of the root field and
+// the roles allowed to use it
+type rootFieldConf struct {
+	qt    QType
+	roles map[string]struct{}
+}
+
+// AddRootField adds a root field of the query (QTQuery) or mutation (QTMutation)
+// type that's resolved outside the database, these fields are not compiled into
+// selects but added to the RootFields of the compiled query. If roles are set
+// then only those roles can use the field.
+func (co *Compiler) AddRootField(name string, qt QType, roles []string) {
+	rm := make(map[string]struct{}, len(roles))
+	for _, r := range roles {
+		rm[r] = struct{}{}
+	}
+
+	co.rf[name] = rootFieldConf{qt: qt, roles: rm}
+}
+
+func (co *Compiler) isRootField(op *graph.Operation, f graph.Field) bool {
+	if f.ParentID != -1 {
+		return false
+	}
+
+	rc, ok := co.rf[f.Name]
+	if !ok {
+		return false
+	}
+
+	return (rc.qt == QTQuery && op.Type == graph.OpQuery) ||
+		(rc.qt == QTMutation && op.Type == graph.OpMutate)
+}
+
+// checkRootFieldRole returns an error if the role
+// is not allowed to use the root field
+func (co *Compiler) checkRootFieldRole(name, role string) error {
+	rm := co.rf[name].roles
+	if len(rm) == 0 {
+		return nil
+	}
+
+	if _, ok := rm[role]; !ok {
+		return blockedErr("root field blocked: %s (%s)", name, role)
+	}
+	return nil
+}
+
+func (co *Compiler) compileRootFields(op *graph.Operation, role string) ([]RootField, error) {
+	var fields []RootField
+
+	for _, f := range op.Fields {
+		if !co.isRootField(op, f) {
+			continue
+		}
+
+		if err := co.checkRootFieldRole(f.Name, role); err != nil {
+			return nil, err
+		}
+
+		rf := RootField{Name: f.Name, FieldName: f.Name}
+
+		if f.Alias != "" {
+			rf.FieldName = f.Alias
+		}
+
+		var aw argWriter
+
+		if err := aw.writeArgs(f.Args); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		rf.args = aw.parts()
+
+		for _, cid := range f.Children {
+			cf, err := newRemoteField(op, op.Fields[cid])
+			if err != nil {
+				return nil, err
+			}
+			rf.Fields = append(rf.Fields, cf)
+		}
+
+		fields = append(fields, rf)
+	}
+
+	return fields, nil
+}
+
+// argWriter converts arguments into JSON split into literal
+// parts and variables
+type argWriter struct {
+	bytes.Buffer
+	p []argPart
+}
+
+func (w *argWriter) parts() []argPart {
+	if w.Len() != 0 {
+		w.p = append(w.p, argPart{lit: append([]byte(nil), w.Bytes()...)})
+		w.Reset()
+	}
+	return w.p
+}
+
+func (w *argWriter) writeArgs(args []graph.Arg) error {
+	w.WriteByte('{')
+	for i, a := range args {
+		if i != 0 {
+			w.WriteByte(',')
+		}
+		w.writeString(a.Name)
+		w.WriteByte(':')
+
+		if err := w.writeValue(a.Val); err != nil {
+			return err
+		}
+	}
+	w.WriteByte('}')
+	return nil
+}
+
+func (w *argWriter) writeValue(n *graph.Node) error {
+	switch n.Type {
+	case graph.NodeStr:
+		w.writeString(n.Val)
+
+	case graph.NodeNum, graph.NodeBool:
+		w.WriteString(n.Val)
+
+	case graph.NodeVar:
+		w.parts()
+		w.p = append(w.p, argPart{v: n.Val})
+
+	case graph.NodeList:
+		w.WriteByte('[')
+		for i, c := range n.Children {
+			if i != 0 {
+				w.WriteByte(',')
+			}
+			if err := w.writeValue(c); err != nil {
+				return err
+			}
+		}
+		w.WriteByte(']')
+
+	case graph.NodeObj:
+		w.WriteByte('{')
+		for i, c := range n.Children {
+			if i != 0 {
+				w.WriteByte(',')
+			}
+			w.writeString(c.Name)
+			w.WriteByte(':')
+			if err := w.writeValue(c); err != nil {
+				return err
+			}
+		}
+		w.WriteByte('}')
+
+	default:
+		return fmt.Errorf("unsupported argument value: %s", n.Val)
+	}
+	return nil
+}
+
+func (w *argWriter) writeString(s string) {
+	v, _ := json.Marshal(s)
+	w.Write(v)
+}
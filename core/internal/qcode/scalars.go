@@ -0,0 +1,174 @@
+package qcode
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dosco/graphjin/core/internal/sdata"
+)
+
+// scalarTypes maps database types to the built-in GraphQL scalar types,
+// types not found here are mapped to String
+var scalarTypes = map[string]string{
+	"smallint":                    "Int",
+	"integer":                     "Int",
+	"int":                         "Int",
+	"int2":                        "Int",
+	"int4":                        "Int",
+	"tinyint":                     "Int",
+	"tinyint(1)":                  "Boolean",
+	"mediumint":                   "Int",
+	"smallserial":                 "Int",
+	"serial":                      "Int",
+	"bigint":                      "BigInt",
+	"int8":                        "BigInt",
+	"bigserial":                   "BigInt",
+	"decimal":                     "Decimal",
+	"numeric":                     "Decimal",
+	"real":                        "Float",
+	"double precision":            "Float",
+	"float":                       "Float",
+	"float4":                      "Float",
+	"float8":                      "Float",
+	"double":                      "Float",
+	"money":                       "Float",
+	"boolean":                     "Boolean",
+	"bool":                        "Boolean",
+	"uuid":                        "UUID",
+	"date":                        "Date",
+	"timestamp":                   "DateTime",
+	"timestamp without time zone": "DateTime",
+	"timestamp with time zone":    "DateTime",
+	"timestamptz":                 "DateTime",
+	"datetime":                    "DateTime",
+	"json":                        "JSON",
+	"jsonb":                       "JSON",
+}
+
+var uuidRe = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+var dateTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05Z07",
+	"2006-01-02 15:04:05",
+}
+
+func newTypeMap(tm map[string]string) map[string]string {
+	m := make(map[string]string, len(tm))
+	for k, v := range tm {
+		m[strings.ToLower(k)] = v
+	}
+	return m
+}
+
+// ScalarType returns the GraphQL scalar type of a column using the
+// type map from the config and then the built-in mappings
+func (co *Compiler) ScalarType(col sdata.DBColumn) string {
+	k := strings.TrimSpace(strings.ToLower(col.Type))
+
+	// types like mysql's tinyint(1) (a boolean) are looked up
+	// with their length before it's removed
+	if v, ok := scalarType(co.tm, k); ok {
+		return v
+	}
+	if i := strings.IndexAny(k, "(["); i != -1 {
+		k = strings.TrimSpace(k[:i])
+	}
+	if v, ok := scalarType(co.tm, k); ok {
+		return v
+	}
+	return "String"
+}
+
+func scalarType(tm map[string]string, k string) (string, bool) {
+	if v, ok := tm[k]; ok {
+		return v, true
+	}
+	v, ok := scalarTypes[k]
+	return v, ok
+}
+
+// checkScalar returns an error if the value is not valid for the scalar
+// type, values of other types are left to the database
+func checkScalar(col sdata.DBColumn, scalar string, v interface{}) error {
+	var ok bool
+
+	// money values can include a currency symbol and separators
+	// that depend on the locale so they are left to the database
+	if strings.EqualFold(strings.TrimSpace(col.Type), "money") {
+		return nil
+	}
+
+	switch scalar {
+	case "Int", "BigInt":
+		switch v1 := v.(type) {
+		case float64:
+			ok = v1 == math.Trunc(v1)
+		case string:
+			_, err := strconv.ParseInt(v1, 10, 64)
+			ok = (err == nil)
+		}
+
+	case "Float", "Decimal":
+		switch v1 := v.(type) {
+		case float64:
+			ok = true
+		case string:
+			_, err := strconv.ParseFloat(v1, 64)
+			ok = (err == nil)
+		}
+
+	case "Boolean":
+		_, ok = v.(bool)
+
+	case "UUID":
+		if v1, isStr := v.(string); isStr {
+			ok = uuidRe.MatchString(v1)
+		}
+
+	case "Date":
+		if v1, isStr := v.(string); isStr {
+			_, err := time.Parse("2006-01-02", v1)
+			ok = (err == nil || specialDate(v1))
+		}
+
+	case "DateTime":
+		if v1, isStr := v.(string); isStr {
+			ok = parseDateTime(v1) || specialDate(v1)
+		}
+
+	default:
+		return nil
+	}
+
+	if ok {
+		return nil
+	}
+	return fmt.Errorf("invalid value for '%s.%s': %v (expecting a %s)",
+		col.Table, col.Name, v, scalar)
+}
+
+func parseDateTime(v string) bool {
+	for _, l := range dateTimeLayouts {
+		if _, err := time.Parse(l, v); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// specialDate returns true for the special date and time
+// inputs supported by Postgres
+func specialDate(v string) bool {
+	switch strings.ToLower(v) {
+	case "now", "today", "tomorrow", "yesterday", "epoch", "infinity", "-infinity":
+		return true
+	}
+	return false
+}
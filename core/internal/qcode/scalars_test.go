@@ -0,0 +1,61 @@
+package qcode
+
+import (
+	"testing"
+
+	"github.com/dosco/graphjin/core/internal/sdata"
+)
+
+func TestScalarType(t *testing.T) {
+	co := &Compiler{tm: newTypeMap(map[string]string{"Citext": "String"})}
+
+	tests := []struct {
+		typ  string
+		want string
+	}{
+		{"tinyint(1)", "Boolean"},
+		{"TINYINT(1)", "Boolean"},
+		{"tinyint(4)", "Int"},
+		{"int(11)", "Int"},
+		{"numeric(7,2)", "Decimal"},
+		{"character varying(255)", "String"},
+		{"timestamp with time zone", "DateTime"},
+		{"integer[]", "Int"},
+		{"money", "Float"},
+		{"citext", "String"},
+	}
+
+	for _, tt := range tests {
+		if got := co.ScalarType(sdata.DBColumn{Type: tt.typ}); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestCheckScalar(t *testing.T) {
+	tests := []struct {
+		typ    string
+		scalar string
+		val    interface{}
+		err    bool
+	}{
+		{"money", "Float", "$1,000.00", false},
+		{"money", "Float", 10.5, false},
+		{"float", "Float", "$1,000.00", true},
+		{"tinyint(1)", "Boolean", true, false},
+		{"tinyint(1)", "Boolean", "yes", true},
+		{"integer", "Int", 1.5, true},
+	}
+
+	for _, tt := range tests {
+		col := sdata.DBColumn{Table: "products", Name: "price", Type: tt.typ}
+		err := checkScalar(col, tt.scalar, tt.val)
+
+		if tt.err && err == nil {
+			t.Errorf("%s %v: expecting an error", tt.typ, tt.val)
+		}
+		if !tt.err && err != nil {
+			t.Errorf("%s %v: %s", tt.typ, tt.val, err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package qcode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dosco/graphjin/core/internal/graph"
+	"github.com/dosco/graphjin/core/internal/sdata"
+)
+
+// TableFunc struct is a database function that returns the rows
+// of a root selector, the arguments are sorted by position
+type TableFunc struct {
+	Name string
+	Args []FuncArg
+
+	// Value is set for functions called by mutations that don't return
+	// table rows, the value returned is of the ReturnType (empty for none)
+	Value      bool
+	ReturnType string
+
+	params []sdata.DBFuncParam
+}
+
+// FuncArg struct is a value passed to a parameter of a function
+type FuncArg struct {
+	Name   string
+	DBType string
+	Type   ValType
+	Val    string
+	pos    int
+}
+
+func (co *Compiler) compileArgTableFunc(sel *Select, arg *graph.Arg) error {
+	fn := sel.TableFunc
+
+	var param *sdata.DBFuncParam
+
+	for i, p := range fn.params {
+		if p.Name.String == arg.Name {
+			param = &fn.params[i]
+			break
+		}
+	}
+
+	if param == nil {
+		return fmt.Errorf("function '%s': invalid argument '%s'", fn.Name, arg.Name)
+	}
+
+	fa := FuncArg{
+		Name:   arg.Name,
+		DBType: param.Type,
+		Val:    arg.Val.Val,
+		pos:    param.ID,
+	}
+
+	switch arg.Val.Type {
+	case graph.NodeStr:
+		fa.Type = ValStr
+	case graph.NodeNum:
+		fa.Type = ValNum
+	case graph.NodeBool:
+		fa.Type = ValBool
+	case graph.NodeVar:
+		fa.Type = ValVar
+	default:
+		return argErr(arg.Name, "string, number, boolean or variable")
+	}
+
+	fn.Args = append(fn.Args, fa)
+	return nil
+}
+
+// validateArgs sorts the arguments by the position of the parameters, arguments
+// can only be left out at the end to use the default values of the parameters
+func (fn *TableFunc) validateArgs() error {
+	sort.Slice(fn.Args, func(i, j int) bool {
+		return fn.Args[i].pos < fn.Args[j].pos
+	})
+
+	for i, a := range fn.Args {
+		p := fn.params[i]
+
+		if a.pos != p.ID {
+			return fmt.Errorf("function '%s': missing argument '%s'", fn.Name, p.Name.String)
+		}
+	}
+	return nil
+}
+
+// validateFuncArgs checks the values of the variables
+// passed to the parameters of the function
+func (co *Compiler) validateFuncArgs(fn *TableFunc, vars Variables) error {
+	if fn == nil {
+		return nil
+	}
+
+	for _, a := range fn.Args {
+		col := sdata.DBColumn{Table: fn.Name, Name: a.Name, Type: a.DBType}
+
+		if a.Type != ValVar || !co.checkedCol(col) {
+			continue
+		}
+
+		v, ok := vars[a.Val]
+		if !ok {
+			continue
+		}
+
+		var val interface{}
+		if err := json.Unmarshal(v, &val); err != nil {
+			return err
+		}
+
+		if err := co.validateValue(col, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddProcedure allows mutations to call the database function or stored
+// procedure, if roles are set then only those roles can call it else all
+// roles except anon can call it
+func (co *Compiler) AddProcedure(name string, roles []string) error {
+	if _, ok := co.s.GetProcedure(name); !ok {
+		return fmt.Errorf("function not found: %s", name)
+	}
+
+	rm := make(map[string]struct{}, len(roles))
+	for _, r := range roles {
+		rm[r] = struct{}{}
+	}
+
+	co.pr[strings.ToLower(name)] = rm
+	return nil
+}
+
+// IsProcedure returns true if the function is called by mutations
+func (co *Compiler) IsProcedure(name string) bool {
+	_, ok := co.pr[strings.ToLower(name)]
+	return ok
+}
+
+// setProcedure sets the function called by the mutation, the selector
+// returns the rows of the table returned by the function if any
+func (co *Compiler) setProcedure(sel *Select, name string) error {
+	var err error
+
+	pr, _ := co.s.GetProcedure(name)
+	sel.TableFunc = &TableFunc{Name: pr.Name, params: pr.Params}
+
+	if pr.Table == "" {
+		sel.TableFunc.Value = true
+		sel.TableFunc.ReturnType = pr.ReturnType
+		sel.Ti = sdata.DBTable{Schema: pr.Schema, Name: pr.Name, Type: "function"}
+		return nil
+	}
+
+	sel.Singular = !pr.SetOf
+	sel.Ti, err = co.s.Find(pr.TableSchema, pr.Table)
+	return err
+}
+
+// isCallBlocked returns an error if the role is not
+// allowed to call the function of the mutation
+func (co *Compiler) isCallBlocked(qc *QCode, sel *Select, role string) error {
+	if qc.Type != QTMutation || sel.TableFunc == nil {
+		return nil
+	}
+
+	rm := co.pr[strings.ToLower(sel.TableFunc.Name)]
+
+	// functions can change anything so anonymous
+	// calls are only allowed if anon is listed
+	if len(rm) == 0 && role != "anon" {
+		return nil
+	}
+
+	if _, ok := rm[role]; !ok {
+		return blockedErr("function blocked: %s (%s)", sel.TableFunc.Name, role)
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package qcode
 
-func GetQType(gql string) (QType, string) {
+// GetQType returns the type and name of the operation named opName in the
+// query, if opName is empty then the first operation is used.
+func GetQType(gql, opName string) (QType, string) {
 	var tok string
 	s := -1
 
@@ -26,13 +28,15 @@ func GetQType(gql string) (QType, string) {
 			continue
 
 		case b == '{':
-			switch tok {
-			case "", "query":
-				return QTQuery, ""
-			case "mutation":
-				return QTMutation, ""
-			case "subscription":
-				return QTSubscription, ""
+			if opName == "" {
+				switch tok {
+				case "", "query":
+					return QTQuery, ""
+				case "mutation":
+					return QTMutation, ""
+				case "subscription":
+					return QTSubscription, ""
+				}
 			}
 			bc++
 
@@ -44,7 +48,7 @@ func GetQType(gql string) (QType, string) {
 
 		case s != -1 && !al(b):
 			ct := gql[s:i]
-			if (bc % 2) == 0 {
+			if (bc%2) == 0 && (opName == "" || ct == opName) {
 				switch tok {
 				case "query":
 					return QTQuery, ct
@@ -62,5 +66,5 @@ func GetQType(gql string) (QType, string) {
 }
 
 func al(b byte) bool {
-	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') || b == '_'
 }
@@ -4,7 +4,8 @@ import "testing"
 
 func TestGetQType(t *testing.T) {
 	type args struct {
-		gql string
+		gql    string
+		opName string
 	}
 	type want struct {
 		op   QType
@@ -52,11 +53,26 @@ func TestGetQType(t *testing.T) {
 			args: args{gql: `# query is good query { query mutation(id: "query {{") { id } subscription }`},
 			want: want{QTUnknown, ""},
 		},
+		ts{
+			name: "multiple operations",
+			args: args{gql: `query getStuff { users { id } } mutation setStuff { users(insert: $data) { id } }`},
+			want: want{QTQuery, "getStuff"},
+		},
+		ts{
+			name: "multiple operations with name",
+			args: args{gql: `query getStuff { users { id } } mutation setStuff { users(insert: $data) { id } }`, opName: "setStuff"},
+			want: want{QTMutation, "setStuff"},
+		},
+		ts{
+			name: "multiple operations with missing name",
+			args: args{gql: `query getStuff { users { id } } mutation setStuff { users(insert: $data) { id } }`, opName: "delStuff"},
+			want: want{QTUnknown, ""},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			op, name := GetQType(tt.args.gql)
+			op, name := GetQType(tt.args.gql, tt.args.opName)
 
 			if op != tt.want.op {
 				t.Errorf("operation = %v, want %v", op, tt.want.op)
@@ -0,0 +1,202 @@
+package qcode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dosco/graphjin/core/internal/sdata"
+)
+
+// checkEnum returns an error if the value is not
+// one of the values of the enum column
+func checkEnum(col sdata.DBColumn, v string) error {
+	for _, ev := range col.Enum {
+		if v == ev {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid value for '%s.%s': '%s' (expecting one of: %s)",
+		col.Table, col.Name, v, strings.Join(col.Enum, ", "))
+}
+
+// checkEnumExp checks the values in an expression on an enum column,
+// variables are checked when the query is executed by ValidateVars
+func checkEnumExp(ex *Exp) error {
+	if len(ex.Col.Enum) == 0 || !valueOp(ex.Op) {
+		return nil
+	}
+
+	switch {
+	case ex.Type == ValStr:
+		return checkEnum(ex.Col, ex.Val)
+
+	case ex.Type == ValList && ex.ListType == ValStr:
+		for _, v := range ex.ListVal {
+			if err := checkEnum(ex.Col, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// valueOp returns true for the operators that compare
+// the column with values of the same type
+func valueOp(op ExpOp) bool {
+	switch op {
+	case OpEquals, OpNotEquals, OpIn, OpNotIn, OpDistinct, OpNotDistinct,
+		OpGreaterThan, OpLesserThan, OpGreaterOrEquals, OpLesserOrEquals:
+		return true
+	}
+	return false
+}
+
+// ValidateVars checks the values of the variables used with enum columns
+// or columns of scalar types like UUID or DateTime in the where expressions,
+// the arguments of functions and the data of inserts and updates. Compiled queries are reused with
+// different variables so the variables are checked each time the query
+// is executed.
+func (co *Compiler) ValidateVars(qc *QCode, vars Variables) error {
+	for i := range qc.Selects {
+		sel := &qc.Selects[i]
+
+		if err := co.validateExp(sel.Where.Exp, vars); err != nil {
+			return err
+		}
+
+		if err := co.validateFuncArgs(sel.TableFunc, vars); err != nil {
+			return err
+		}
+	}
+
+	if len(qc.Mutates) == 0 {
+		return nil
+	}
+
+	var data interface{}
+
+	if v, ok := vars[qc.ActionVar]; ok {
+		if err := json.Unmarshal(v, &data); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range qc.Mutates {
+		if err := co.validateMutate(m, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (co *Compiler) validateExp(ex *Exp, vars Variables) error {
+	if ex == nil {
+		return nil
+	}
+
+	for _, c := range ex.Children {
+		if err := co.validateExp(c, vars); err != nil {
+			return err
+		}
+	}
+
+	if ex.Type != ValVar || !valueOp(ex.Op) || !co.checkedCol(ex.Col) {
+		return nil
+	}
+
+	v, ok := vars[ex.Val]
+	if !ok {
+		return nil
+	}
+
+	var val interface{}
+	if err := json.Unmarshal(v, &val); err != nil {
+		return err
+	}
+	return co.validateValue(ex.Col, val)
+}
+
+func (co *Compiler) validateMutate(m Mutate, data interface{}) error {
+	if m.Type != MTInsert && m.Type != MTUpdate && m.Type != MTUpsert {
+		return nil
+	}
+
+	var cols []MColumn
+
+	for _, c := range m.Cols {
+		// presets are set from the config
+		if c.Value == "" && co.checkedCol(c.Col) {
+			cols = append(cols, c)
+		}
+	}
+
+	if len(cols) == 0 {
+		return nil
+	}
+
+	return walkData(data, m.Path, func(obj map[string]interface{}) error {
+		for _, c := range cols {
+			if v, ok := obj[c.FieldName]; ok {
+				if err := co.validateValue(c.Col, v); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// walkData calls fn with each object found at the path, lists found
+// along the path are walked item by item
+func walkData(v interface{}, path []string, fn func(map[string]interface{}) error) error {
+	switch v1 := v.(type) {
+	case []interface{}:
+		for _, item := range v1 {
+			if err := walkData(item, path, fn); err != nil {
+				return err
+			}
+		}
+
+	case map[string]interface{}:
+		if len(path) == 0 {
+			return fn(v1)
+		}
+		return walkData(v1[path[0]], path[1:], fn)
+	}
+	return nil
+}
+
+// checkedCol returns true if the values of the column are checked
+func (co *Compiler) checkedCol(col sdata.DBColumn) bool {
+	return len(col.Enum) != 0 || co.ScalarType(col) != "String"
+}
+
+func (co *Compiler) validateValue(col sdata.DBColumn, v interface{}) error {
+	scalar := co.ScalarType(col)
+
+	switch v1 := v.(type) {
+	case nil:
+		return nil
+
+	case []interface{}:
+		if scalar == "JSON" {
+			return nil
+		}
+		for _, item := range v1 {
+			if err := co.validateValue(col, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(col.Enum) == 0 {
+		return checkScalar(col, scalar, v)
+	}
+
+	if v1, ok := v.(string); ok {
+		return checkEnum(col, v1)
+	}
+	return fmt.Errorf("invalid value for '%s.%s': expecting a string", col.Table, col.Name)
+}
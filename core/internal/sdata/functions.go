@@ -0,0 +1,308 @@
+package sdata
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DBTableFunction struct describes a function that returns a set of rows of a
+// table (RETURNS SETOF table) or of the columns it defines (RETURNS TABLE(...)).
+// Functions returning columns are added as tables with the name of the function
+type DBTableFunction struct {
+	Schema      string
+	Name        string
+	Params      []DBFuncParam
+	TableSchema string
+	Table       string
+	Columns     []DBColumn
+}
+
+type funcArg struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Mode string `json:"mode"`
+}
+
+// DiscoverTableFunctions returns the functions that return a set of table rows,
+// these are only supported on Postgres
+func DiscoverTableFunctions(db *sql.DB, dbtype string, blockList []string) ([]DBTableFunction, error) {
+	if dbtype == "mysql" {
+		return nil, nil
+	}
+
+	rows, err := db.Query(postgresTableFunctionsStmt)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching table functions: %s", err)
+	}
+	defer rows.Close()
+
+	var funcs []DBTableFunction
+	fm := make(map[string]struct{})
+
+	for rows.Next() {
+		var fn DBTableFunction
+		var args string
+
+		err = rows.Scan(&fn.Schema, &fn.Name, &fn.TableSchema, &fn.Table, &args)
+		if err != nil {
+			return nil, err
+		}
+
+		// only the first of overloaded functions is used
+		if _, ok := fm[fn.Name]; ok || isInList(fn.Name, blockList) {
+			continue
+		}
+		fm[fn.Name] = struct{}{}
+
+		if err := fn.setArgs(args); err != nil {
+			return nil, fmt.Errorf("function: %s: %w", fn.Name, err)
+		}
+		funcs = append(funcs, fn)
+	}
+
+	return funcs, rows.Err()
+}
+
+// setArgs sets the parameters and the returned columns using the list of
+// arguments, arguments of the mode 't' (TABLE) or 'o' (OUT) are columns
+func (fn *DBTableFunction) setArgs(v string) error {
+	args, err := parseFuncArgs(v)
+	if err != nil {
+		return err
+	}
+
+	for i, a := range args {
+		switch a.Mode {
+		case "t", "o":
+			fn.Columns = append(fn.Columns, DBColumn{
+				Schema: fn.Schema,
+				Table:  fn.Name,
+				Name:   a.Name,
+				Key:    strings.ToLower(a.Name),
+				Type:   a.Type,
+			})
+
+		default:
+			fn.Params = append(fn.Params, newFuncParam(i+1, a.Name, a.Type))
+		}
+	}
+	return nil
+}
+
+func parseFuncArgs(v string) ([]funcArg, error) {
+	var args []funcArg
+
+	if err := json.Unmarshal([]byte(v), &args); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// newFuncParam returns a parameter at the position, unnamed
+// parameters are named by their position eg. arg1
+func newFuncParam(pos int, name, _type string) DBFuncParam {
+	if name == "" {
+		name = fmt.Sprintf("arg%d", pos)
+	}
+	return DBFuncParam{
+		ID:   pos,
+		Name: sql.NullString{String: name, Valid: true},
+		Type: _type,
+	}
+}
+
+// setTableFunctions sets the functions that return table rows, functions
+// returning their own columns are added as tables
+func (di *DBInfo) setTableFunctions(funcs []DBTableFunction) {
+	di.TableFunctions = funcs
+
+	for _, fn := range funcs {
+		if fn.Table != "" || len(fn.Columns) == 0 {
+			continue
+		}
+
+		if _, err := di.GetTable(fn.Schema, fn.Name); err == nil {
+			continue
+		}
+
+		cols := make([]DBColumn, len(fn.Columns))
+		copy(cols, fn.Columns)
+
+		di.AddTable(NewDBTable(fn.Schema, fn.Name, "function", cols))
+	}
+}
+
+// ResultTable returns the schema and the name of the table
+// returned by the function
+func (fn *DBTableFunction) ResultTable() (string, string) {
+	if fn.Table == "" {
+		return fn.Schema, fn.Name
+	}
+	return fn.TableSchema, fn.Table
+}
+
+// GetTableFunction returns the function returning table rows
+// with the name
+func (s *DBSchema) GetTableFunction(name string) (DBTableFunction, bool) {
+	fn, ok := s.tfm[strings.ToLower(name)]
+	return fn, ok
+}
+
+// GetTableFunctions returns the functions returning table
+// rows sorted by name
+func (s *DBSchema) GetTableFunctions() []DBTableFunction {
+	funcs := make([]DBTableFunction, 0, len(s.tfm))
+
+	for _, fn := range s.tfm {
+		funcs = append(funcs, fn)
+	}
+
+	sort.Slice(funcs, func(i, j int) bool {
+		return funcs[i].Name < funcs[j].Name
+	})
+	return funcs
+}
+
+// DBProcedure struct describes a function (Postgres) or a stored procedure (MySQL)
+// that's called by a mutation. Table is set for functions returning rows of a
+// table and ReturnType for the rest, it's empty when nothing is returned
+type DBProcedure struct {
+	Schema      string
+	Name        string
+	Params      []DBFuncParam
+	ReturnType  string
+	SetOf       bool
+	TableSchema string
+	Table       string
+}
+
+// DiscoverProcedures returns the functions (Postgres) or the stored
+// procedures (MySQL) that can be called by mutations
+func DiscoverProcedures(db *sql.DB, dbtype string, blockList []string) ([]DBProcedure, error) {
+	var procs []DBProcedure
+	var err error
+
+	switch dbtype {
+	case "mysql":
+		procs, err = discoverMySQLProcedures(db, blockList)
+	default:
+		procs, err = discoverPostgresProcedures(db, blockList)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error fetching procedures: %s", err)
+	}
+	return procs, nil
+}
+
+func discoverPostgresProcedures(db *sql.DB, blockList []string) ([]DBProcedure, error) {
+	rows, err := db.Query(postgresProceduresStmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var procs []DBProcedure
+	pm := make(map[string]struct{})
+
+	for rows.Next() {
+		var pr DBProcedure
+		var v string
+
+		err = rows.Scan(&pr.Schema, &pr.Name, &pr.SetOf, &pr.ReturnType,
+			&pr.TableSchema, &pr.Table, &v)
+		if err != nil {
+			return nil, err
+		}
+
+		// only the first of overloaded functions is used
+		if _, ok := pm[pr.Name]; ok || isInList(pr.Name, blockList) {
+			continue
+		}
+		pm[pr.Name] = struct{}{}
+
+		args, err := parseFuncArgs(v)
+		if err != nil {
+			return nil, fmt.Errorf("function: %s: %w", pr.Name, err)
+		}
+
+		for i, a := range args {
+			if a.Mode == "o" || a.Mode == "t" {
+				continue
+			}
+			pr.Params = append(pr.Params, newFuncParam(i+1, a.Name, a.Type))
+		}
+
+		if pr.Table != "" {
+			pr.ReturnType = ""
+		}
+		procs = append(procs, pr)
+	}
+
+	return procs, rows.Err()
+}
+
+// discoverMySQLProcedures returns the stored procedures, procedures with
+// OUT or INOUT parameters are skipped since their values cannot be returned
+func discoverMySQLProcedures(db *sql.DB, blockList []string) ([]DBProcedure, error) {
+	rows, err := db.Query(mysqlProceduresStmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var procs []DBProcedure
+	pm := make(map[string]int)
+	skip := make(map[string]struct{})
+
+	for rows.Next() {
+		var schema, name, pname, ptype, pmode string
+
+		if err := rows.Scan(&schema, &name, &pname, &ptype, &pmode); err != nil {
+			return nil, err
+		}
+
+		if isInList(name, blockList) {
+			continue
+		}
+
+		i, ok := pm[name]
+		if !ok {
+			procs = append(procs, DBProcedure{Schema: schema, Name: name})
+			i = len(procs) - 1
+			pm[name] = i
+		}
+
+		switch pmode {
+		case "":
+			continue
+		case "IN":
+			pr := &procs[i]
+			pr.Params = append(pr.Params, newFuncParam(len(pr.Params)+1, pname, ptype))
+		default:
+			skip[name] = struct{}{}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var res []DBProcedure
+
+	for _, pr := range procs {
+		if _, ok := skip[pr.Name]; !ok {
+			res = append(res, pr)
+		}
+	}
+	return res, nil
+}
+
+// GetProcedure returns the function or stored procedure with the name
+func (s *DBSchema) GetProcedure(name string) (DBProcedure, bool) {
+	pr, ok := s.pm[strings.ToLower(name)]
+	return pr, ok
+}
@@ -0,0 +1,127 @@
+package sdata
+
+import (
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/iterator"
+	"gonum.org/v1/gonum/graph/multi"
+)
+
+// relGraph is the weighted directed multigraph of the relationships
+// between tables. Its nodes and lines are kept in slices instead of the
+// maps used by the gonum graphs so iterating over them doesn't rely on
+// gonum's map iterators (these crash on newer Go runtimes) and the
+// paths found don't depend on the order of map iteration.
+type relGraph struct {
+	nodes []graph.Node
+	from  map[int64][]graph.Node
+	to    map[int64][]graph.Node
+	lines map[[2]int64][]graph.WeightedLine
+	lid   int64
+}
+
+func newRelGraph() *relGraph {
+	return &relGraph{
+		from:  make(map[int64][]graph.Node),
+		to:    make(map[int64][]graph.Node),
+		lines: make(map[[2]int64][]graph.WeightedLine),
+	}
+}
+
+// NewNode returns a new node, node ids are
+// the index of the node in the graph
+func (g *relGraph) NewNode() graph.Node {
+	return multi.Node(len(g.nodes))
+}
+
+func (g *relGraph) AddNode(n graph.Node) {
+	g.nodes = append(g.nodes, n)
+}
+
+// NewWeightedLine returns a new line with a unique id, the
+// line is only added to the graph by SetWeightedLine
+func (g *relGraph) NewWeightedLine(from, to graph.Node, weight float64) graph.WeightedLine {
+	l := multi.WeightedLine{F: from, T: to, W: weight, UID: g.lid}
+	g.lid++
+	return l
+}
+
+func (g *relGraph) SetWeightedLine(l graph.WeightedLine) {
+	fid, tid := l.From().ID(), l.To().ID()
+	k := [2]int64{fid, tid}
+
+	if _, ok := g.lines[k]; !ok {
+		g.from[fid] = append(g.from[fid], l.To())
+		g.to[tid] = append(g.to[tid], l.From())
+	}
+	g.lines[k] = append(g.lines[k], l)
+}
+
+func (g *relGraph) Node(id int64) graph.Node {
+	if id < 0 || id >= int64(len(g.nodes)) {
+		return nil
+	}
+	return g.nodes[id]
+}
+
+// Nodes, From and To return copies of the node lists
+// since the path search reorders the nodes it's given
+
+func (g *relGraph) Nodes() graph.Nodes {
+	return iterator.NewOrderedNodes(copyNodes(g.nodes))
+}
+
+func (g *relGraph) From(id int64) graph.Nodes {
+	return iterator.NewOrderedNodes(copyNodes(g.from[id]))
+}
+
+func (g *relGraph) To(id int64) graph.Nodes {
+	return iterator.NewOrderedNodes(copyNodes(g.to[id]))
+}
+
+func (g *relGraph) HasEdgeBetween(xid, yid int64) bool {
+	return g.HasEdgeFromTo(xid, yid) || g.HasEdgeFromTo(yid, xid)
+}
+
+func (g *relGraph) HasEdgeFromTo(uid, vid int64) bool {
+	_, ok := g.lines[[2]int64{uid, vid}]
+	return ok
+}
+
+func (g *relGraph) Edge(uid, vid int64) graph.Edge {
+	return g.WeightedEdge(uid, vid)
+}
+
+func (g *relGraph) WeightedEdge(uid, vid int64) graph.WeightedEdge {
+	lines, ok := g.lines[[2]int64{uid, vid}]
+	if !ok {
+		return nil
+	}
+	return multi.WeightedEdge{
+		F:             g.Node(uid),
+		T:             g.Node(vid),
+		WeightedLines: iterator.NewOrderedWeightedLines(lines),
+	}
+}
+
+// WeightedLines returns the lines from u to v
+func (g *relGraph) WeightedLines(uid, vid int64) graph.WeightedLines {
+	return iterator.NewOrderedWeightedLines(g.lines[[2]int64{uid, vid}])
+}
+
+// Weight returns the sum of the weights of the lines from x to y
+func (g *relGraph) Weight(xid, yid int64) (float64, bool) {
+	lines, ok := g.lines[[2]int64{xid, yid}]
+	if !ok {
+		return 0, false
+	}
+
+	var w float64
+	for _, l := range lines {
+		w += l.Weight()
+	}
+	return w, true
+}
+
+func copyNodes(nodes []graph.Node) []graph.Node {
+	return append([]graph.Node(nil), nodes...)
+}
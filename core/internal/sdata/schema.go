@@ -5,8 +5,6 @@ package sdata
 import (
 	"fmt"
 	"strings"
-
-	"gonum.org/v1/gonum/graph/multi"
 )
 
 type edgeInfo struct {
@@ -19,19 +17,21 @@ type nodeInfo struct {
 }
 
 type DBSchema struct {
-	typ    string                       // db type
-	ver    int                          // db version
-	schema string                       // db schema
-	name   string                       // db name
-	tables []DBTable                    // tables
-	vt     map[string]VirtualTable      // for polymorphic relationships
-	fm     map[string]DBFunction        // db functions
-	tindex map[string]nodeInfo          // table index
-	ai     map[string]nodeInfo          // table alias index
-	re     map[int64]TEdge              // recursive edges
-	ae     map[int64]TEdge              // all other edges
-	ei     map[string][]edgeInfo        // edges index
-	rg     *multi.WeightedDirectedGraph // relationship graph
+	typ    string                     // db type
+	ver    int                        // db version
+	schema string                     // db schema
+	name   string                     // db name
+	tables []DBTable                  // tables
+	vt     map[string]VirtualTable    // for polymorphic relationships
+	fm     map[string]DBFunction      // db functions
+	tfm    map[string]DBTableFunction // db functions returning table rows
+	pm     map[string]DBProcedure     // db functions called by mutations
+	tindex map[string]nodeInfo        // table index
+	ai     map[string]nodeInfo        // table alias index
+	re     map[int64]TEdge            // recursive edges
+	ae     map[int64]TEdge            // all other edges
+	ei     map[string][]edgeInfo      // edges index
+	rg     *relGraph                  // relationship graph
 }
 
 type RelType int
@@ -83,23 +83,15 @@ func NewDBSchema(
 		vt:     make(map[string]VirtualTable),
 		tindex: make(map[string]nodeInfo),
 		ai:     make(map[string]nodeInfo),
+		fm:     make(map[string]DBFunction),
+		tfm:    make(map[string]DBTableFunction),
+		pm:     make(map[string]DBProcedure),
 		re:     make(map[int64]TEdge),
 		ae:     make(map[int64]TEdge),
 		ei:     make(map[string][]edgeInfo),
-		rg:     multi.NewWeightedDirectedGraph(),
+		rg:     newRelGraph(),
 	}
 
-	// schema.rg.EdgeWeightFunc = func(e graph.WeightedLines) float64 {
-	// 	var min float64 = 10
-	// 	for e.Next() {
-	// 		l := e.WeightedLine()
-	// 		if l.Weight() < min {
-	// 			min = l.Weight()
-	// 		}
-	// 	}
-	// 	return min
-	// }
-
 	var nids []int64
 
 	for _, t := range info.Tables {
@@ -130,6 +122,16 @@ func NewDBSchema(
 		}
 	}
 
+	for _, f := range info.TableFunctions {
+		if _, err := schema.Find(f.ResultTable()); err == nil {
+			schema.tfm[strings.ToLower(f.Name)] = f
+		}
+	}
+
+	for _, pr := range info.Procedures {
+		schema.pm[strings.ToLower(pr.Name)] = pr
+	}
+
 	return schema, nil
 }
 
@@ -0,0 +1,94 @@
+package sdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// snapshotVersion is incremented when the snapshot format changes
+const snapshotVersion = 1
+
+// snapshot struct contains the discovered database schema, the columns are saved
+// as discovered and not with the changes made by the config (eg. foreign keys)
+type snapshot struct {
+	Version   int          `json:"version"`
+	DBType    string       `json:"db_type"`
+	DBVersion int          `json:"db_version"`
+	DBSchema  string       `json:"db_schema"`
+	DBName    string       `json:"db_name"`
+	Columns   []DBColumn   `json:"columns"`
+	Functions []DBFunction `json:"functions"`
+
+	// TableComments are keyed by the schema and the table name (schema:table)
+	TableComments map[string]string `json:"table_comments,omitempty"`
+
+	TableFunctions []DBTableFunction `json:"table_functions,omitempty"`
+	Procedures     []DBProcedure     `json:"procedures,omitempty"`
+}
+
+// WriteSnapshot writes the discovered database schema as JSON, the columns
+// are sorted so that snapshots of the same schema are identical
+func (di *DBInfo) WriteSnapshot(w io.Writer) error {
+	cols := make([]DBColumn, len(di.columns))
+	copy(cols, di.columns)
+
+	sort.Slice(cols, func(i, j int) bool {
+		ci, cj := cols[i], cols[j]
+		if ci.Schema != cj.Schema {
+			return ci.Schema < cj.Schema
+		}
+		if ci.Table != cj.Table {
+			return ci.Table < cj.Table
+		}
+		return ci.Name < cj.Name
+	})
+
+	ss := snapshot{
+		Version:   snapshotVersion,
+		DBType:    di.Type,
+		DBVersion: di.Version,
+		DBSchema:  di.Schema,
+		DBName:    di.Name,
+		Columns:   cols,
+		Functions: di.Functions,
+
+		TableComments:  di.comments,
+		TableFunctions: di.TableFunctions,
+		Procedures:     di.Procedures,
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(&ss)
+}
+
+// ReadSnapshot returns the database schema saved using WriteSnapshot
+func ReadSnapshot(r io.Reader, blockList []string) (*DBInfo, error) {
+	var ss snapshot
+
+	if err := json.NewDecoder(r).Decode(&ss); err != nil {
+		return nil, fmt.Errorf("schema snapshot: %w", err)
+	}
+
+	if ss.Version != snapshotVersion {
+		return nil, fmt.Errorf("schema snapshot: unsupported version %d", ss.Version)
+	}
+
+	di := NewDBInfo(
+		ss.DBType,
+		ss.DBVersion,
+		ss.DBSchema,
+		ss.DBName,
+		ss.Columns,
+		ss.Functions,
+		blockList)
+
+	di.setTableComments(ss.TableComments)
+	di.setTableFunctions(ss.TableFunctions)
+	di.Procedures = ss.Procedures
+
+	return di, nil
+}
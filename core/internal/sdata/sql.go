@@ -63,7 +63,14 @@ SELECT
 		WHEN co.contype = ('f'::char) 
 		THEN (SELECT f.attname FROM pg_attribute f WHERE f.attnum = co.confkey[1] and f.attrelid = co.confrelid)
 		ELSE ''::text
-	END) AS foreignkey_column
+	END) AS foreignkey_column,
+	COALESCE(col_description(c.oid, f.attnum), '') AS "comment",
+	COALESCE((
+		SELECT json_agg(e.enumlabel ORDER BY e.enumsortorder)::text
+		FROM pg_enum e 
+		WHERE e.enumtypid = f.atttypid 
+			OR e.enumtypid = (SELECT t.typelem FROM pg_type t WHERE t.oid = f.atttypid)
+	), '') AS enum_values
 FROM 
 	pg_attribute f
 	JOIN pg_class c ON c.oid = f.attrelid  
@@ -78,6 +85,85 @@ WHERE
 	AND f.attisdropped = false;
 `
 
+const postgresTableCommentsStmt = `
+SELECT
+	n.nspname as "schema",
+	c.relname as "table",
+	obj_description(c.oid, 'pg_class') AS "comment"
+FROM
+	pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE
+	c.relkind IN ('r', 'v', 'm', 'f')
+	AND n.nspname NOT IN ('information_schema', 'pg_catalog')
+	AND obj_description(c.oid, 'pg_class') IS NOT NULL;
+`
+
+const postgresTableFunctionsStmt = `
+SELECT
+	n.nspname AS func_schema,
+	p.proname AS func_name,
+	COALESCE(tn.nspname, '') AS table_schema,
+	COALESCE(tc.relname, '') AS table_name,
+	COALESCE((
+		SELECT json_agg(json_build_object(
+			'name', COALESCE(a.name, ''),
+			'type', format_type(a.type, NULL),
+			'mode', COALESCE(a.mode, 'i')) ORDER BY a.pos)
+		FROM unnest(
+			COALESCE(p.proallargtypes, p.proargtypes::oid[]),
+			p.proargnames,
+			p.proargmodes) WITH ORDINALITY AS a(type, name, mode, pos)
+	), '[]') AS func_args
+FROM
+	pg_proc p
+	JOIN pg_namespace n ON n.oid = p.pronamespace
+	JOIN pg_type t ON t.oid = p.prorettype
+	LEFT JOIN pg_class tc ON tc.oid = t.typrelid AND tc.relkind IN ('r', 'v', 'm', 'p', 'f')
+	LEFT JOIN pg_namespace tn ON tn.oid = tc.relnamespace
+WHERE
+	p.proretset
+	AND n.nspname NOT IN ('information_schema', 'pg_catalog')
+	AND (tc.oid IS NOT NULL OR 't' = ANY (p.proargmodes))
+ORDER BY
+	p.proname, p.oid;
+`
+
+const postgresProceduresStmt = `
+SELECT
+	n.nspname AS func_schema,
+	p.proname AS func_name,
+	p.proretset AS returns_set,
+	(CASE
+		WHEN p.prorettype = 'void'::regtype THEN ''
+		ELSE format_type(p.prorettype, NULL)
+	END) AS return_type,
+	COALESCE(tn.nspname, '') AS table_schema,
+	COALESCE(tc.relname, '') AS table_name,
+	COALESCE((
+		SELECT json_agg(json_build_object(
+			'name', COALESCE(a.name, ''),
+			'type', format_type(a.type, NULL),
+			'mode', COALESCE(a.mode, 'i')) ORDER BY a.pos)
+		FROM unnest(
+			COALESCE(p.proallargtypes, p.proargtypes::oid[]),
+			p.proargnames,
+			p.proargmodes) WITH ORDINALITY AS a(type, name, mode, pos)
+	), '[]') AS func_args
+FROM
+	pg_proc p
+	JOIN pg_namespace n ON n.oid = p.pronamespace
+	LEFT JOIN pg_type t ON t.oid = p.prorettype
+	LEFT JOIN pg_class tc ON tc.oid = t.typrelid AND tc.relkind IN ('r', 'v', 'm', 'p', 'f')
+	LEFT JOIN pg_namespace tn ON tn.oid = tc.relnamespace
+WHERE
+	n.nspname NOT IN ('information_schema', 'pg_catalog')
+	AND p.prorettype NOT IN ('record'::regtype, 'trigger'::regtype)
+	AND NOT EXISTS (SELECT 1 FROM pg_aggregate ag WHERE ag.aggfnoid = p.oid)
+ORDER BY
+	p.proname, p.oid;
+`
+
 const mysqlInfo = `
 SELECT 
 		a.c as db_version, 
@@ -110,7 +196,12 @@ SELECT
 	END) AS full_text,
 	'' AS foreignkey_schema,
 	'' AS foreignkey_table,
-	'' AS foreignkey_column
+	'' AS foreignkey_column,
+	col.column_comment AS "comment",
+	(CASE
+		WHEN col.data_type = 'enum' THEN col.column_type
+		ELSE ''
+	END) AS enum_values
 FROM 
 	information_schema.columns col
 LEFT JOIN information_schema.statistics stat ON col.table_schema = stat.table_schema
@@ -147,7 +238,9 @@ SELECT
 	(CASE
 		WHEN tc.constraint_type = 'FOREIGN KEY' THEN kcu.referenced_column_name
 		ELSE ''
-	END) AS foreignkey_column
+	END) AS foreignkey_column,
+	'' AS "comment",
+	'' AS enum_values
 FROM 
 	information_schema.key_column_usage kcu
 JOIN
@@ -157,3 +250,35 @@ JOIN
 WHERE
 	kcu.constraint_schema NOT IN ('information_schema', 'performance_schema', 'mysql', 'sys');
 `
+
+const mysqlTableCommentsStmt = `
+SELECT
+	table_schema as "schema",
+	table_name as "table",
+	table_comment AS "comment"
+FROM
+	information_schema.tables
+WHERE
+	table_type = 'BASE TABLE'
+	AND table_comment != ''
+	AND table_schema NOT IN ('information_schema', 'performance_schema', 'mysql', 'sys');
+`
+
+const mysqlProceduresStmt = `
+SELECT
+	r.routine_schema AS func_schema,
+	r.routine_name AS func_name,
+	COALESCE(p.parameter_name, '') AS param_name,
+	COALESCE(p.data_type, '') AS param_type,
+	COALESCE(p.parameter_mode, '') AS param_mode
+FROM
+	information_schema.routines r
+LEFT JOIN information_schema.parameters p ON p.specific_schema = r.routine_schema
+	AND p.specific_name = r.routine_name
+	AND p.ordinal_position > 0
+WHERE
+	r.routine_type = 'PROCEDURE'
+	AND r.routine_schema NOT IN ('information_schema', 'performance_schema', 'mysql', 'sys')
+ORDER BY
+	r.routine_name, p.ordinal_position;
+`
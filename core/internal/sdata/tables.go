@@ -2,6 +2,7 @@ package sdata
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -16,8 +17,18 @@ type DBInfo struct {
 	Tables    []DBTable
 	Functions []DBFunction
 	VTables   []VirtualTable
-	colMap    map[string]int
-	tableMap  map[string]int
+
+	// TableFunctions are the functions returning table rows
+	TableFunctions []DBTableFunction
+
+	// Procedures are the functions or stored procedures
+	// that can be called by mutations
+	Procedures []DBProcedure
+
+	columns  []DBColumn
+	comments map[string]string
+	colMap   map[string]int
+	tableMap map[string]int
 }
 
 type DBTable struct {
@@ -29,6 +40,7 @@ type DBTable struct {
 	SecondaryCol DBColumn
 	FullText     []DBColumn
 	Blocked      bool
+	Comment      string
 	colMap       map[string]int
 }
 
@@ -52,6 +64,9 @@ func GetDBInfo(
 	var dbSchema, dbName string
 	var cols []DBColumn
 	var funcs []DBFunction
+	var tfuncs []DBTableFunction
+	var procs []DBProcedure
+	var comments map[string]string
 
 	g := errgroup.Group{}
 
@@ -76,9 +91,21 @@ func GetDBInfo(
 			return err
 		}
 
+		if comments, err = DiscoverTableComments(db, dbType); err != nil {
+			return err
+		}
+
 		if funcs, err = DiscoverFunctions(db, blockList); err != nil {
 			return err
 		}
+
+		if tfuncs, err = DiscoverTableFunctions(db, dbType, blockList); err != nil {
+			return err
+		}
+
+		if procs, err = DiscoverProcedures(db, dbType, blockList); err != nil {
+			return err
+		}
 		return nil
 	})
 
@@ -95,6 +122,10 @@ func GetDBInfo(
 		funcs,
 		blockList)
 
+	di.setTableComments(comments)
+	di.setTableFunctions(tfuncs)
+	di.Procedures = procs
+
 	return di, nil
 }
 
@@ -113,6 +144,7 @@ func NewDBInfo(
 		Schema:    dbSchema,
 		Name:      dbName,
 		Functions: funcs,
+		columns:   cols,
 		colMap:    make(map[string]int),
 		tableMap:  make(map[string]int),
 	}
@@ -176,6 +208,47 @@ func (di *DBInfo) AddTable(t DBTable) {
 	}
 }
 
+// AddColumn adds a computed column to the table
+func (di *DBInfo) AddColumn(col DBColumn) error {
+	t, err := di.GetTable(col.Schema, col.Table)
+	if err != nil {
+		return err
+	}
+
+	col.Schema = t.Schema
+	col.Table = t.Name
+	col.Key = strings.ToLower(col.Name)
+
+	// computed columns are replaced when the config is applied again
+	if i, ok := t.colMap[col.Key]; ok {
+		if t.Columns[i].SQL == "" {
+			return fmt.Errorf("column: '%s.%s.%s' already exists", col.Schema, col.Table, col.Name)
+		}
+		t.Columns[i] = col
+		return nil
+	}
+
+	i := len(t.Columns)
+	t.Columns = append(t.Columns, col)
+	t.colMap[col.Key] = i
+
+	di.colMap[(col.Schema + ":" + col.Table + ":" + col.Name)] = i
+	di.colMap[(":" + col.Table + ":" + col.Name)] = i
+	return nil
+}
+
+// setTableComments sets the comments of the tables, the comments are
+// keyed by the schema and the table name (schema:table)
+func (di *DBInfo) setTableComments(comments map[string]string) {
+	di.comments = comments
+
+	for k, v := range comments {
+		if tid, ok := di.tableMap[k]; ok {
+			di.Tables[tid].Comment = v
+		}
+	}
+}
+
 func (di *DBInfo) GetColumn(schema, table, column string) (*DBColumn, error) {
 	t, err := di.GetTable(schema, table)
 	if err != nil {
@@ -214,6 +287,12 @@ type DBColumn struct {
 	Blocked    bool
 	Table      string
 	Schema     string
+	Comment    string
+	Enum       []string
+
+	// SQL is the expression of a computed column, computed
+	// columns are not stored in the database
+	SQL string
 }
 
 func DiscoverColumns(db *sql.DB, dbtype string, blockList []string) ([]DBColumn, error) {
@@ -236,13 +315,18 @@ func DiscoverColumns(db *sql.DB, dbtype string, blockList []string) ([]DBColumn,
 
 	for rows.Next() {
 		var c DBColumn
+		var enum string
 
-		err = rows.Scan(&c.Schema, &c.Table, &c.Name, &c.Type, &c.NotNull, &c.PrimaryKey, &c.UniqueKey, &c.Array, &c.FullText, &c.FKeySchema, &c.FKeyTable, &c.FKeyCol)
+		err = rows.Scan(&c.Schema, &c.Table, &c.Name, &c.Type, &c.NotNull, &c.PrimaryKey, &c.UniqueKey, &c.Array, &c.FullText, &c.FKeySchema, &c.FKeyTable, &c.FKeyCol, &c.Comment, &enum)
 
 		if err != nil {
 			return nil, err
 		}
 
+		if c.Enum, err = parseEnum(dbtype, enum); err != nil {
+			return nil, fmt.Errorf("column: %s.%s: %w", c.Table, c.Name, err)
+		}
+
 		k := (c.Schema + ":" + c.Table + ":" + c.Name)
 		v := cmap[k]
 		if v.Key == "" {
@@ -277,6 +361,12 @@ func DiscoverColumns(db *sql.DB, dbtype string, blockList []string) ([]DBColumn,
 		if c.FKeyCol != "" {
 			v.FKeyCol = c.FKeyCol
 		}
+		if c.Comment != "" {
+			v.Comment = c.Comment
+		}
+		if len(c.Enum) != 0 {
+			v.Enum = c.Enum
+		}
 		cmap[k] = v
 	}
 
@@ -288,6 +378,90 @@ func DiscoverColumns(db *sql.DB, dbtype string, blockList []string) ([]DBColumn,
 	return cols, nil
 }
 
+// parseEnum returns the values of an enum, on Postgres the values are
+// a JSON array and on MySQL the column type eg. enum('a','b')
+func parseEnum(dbtype, v string) ([]string, error) {
+	var values []string
+
+	if v == "" {
+		return nil, nil
+	}
+
+	if dbtype != "mysql" {
+		err := json.Unmarshal([]byte(v), &values)
+		return values, err
+	}
+
+	if !strings.HasPrefix(v, "enum(") || !strings.HasSuffix(v, ")") {
+		return nil, fmt.Errorf("invalid enum: %s", v)
+	}
+	v = v[5 : len(v)-1]
+
+	for len(v) != 0 {
+		if v[0] != '\'' {
+			return nil, fmt.Errorf("invalid enum value: %s", v)
+		}
+
+		var sb strings.Builder
+		i := 1
+
+		for ; i < len(v); i++ {
+			if v[i] != '\'' {
+				sb.WriteByte(v[i])
+				continue
+			}
+			// quotes are escaped by doubling them
+			if i+1 < len(v) && v[i+1] == '\'' {
+				sb.WriteByte('\'')
+				i++
+				continue
+			}
+			break
+		}
+
+		if i >= len(v) {
+			return nil, fmt.Errorf("invalid enum value: %s", v)
+		}
+		values = append(values, sb.String())
+
+		v = strings.TrimPrefix(v[i+1:], ",")
+	}
+
+	return values, nil
+}
+
+// DiscoverTableComments returns the comments set on tables keyed
+// by the schema and the table name (schema:table)
+func DiscoverTableComments(db *sql.DB, dbtype string) (map[string]string, error) {
+	var sqlStmt string
+
+	switch dbtype {
+	case "mysql":
+		sqlStmt = mysqlTableCommentsStmt
+	default:
+		sqlStmt = postgresTableCommentsStmt
+	}
+
+	rows, err := db.Query(sqlStmt)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching table comments: %s", err)
+	}
+	defer rows.Close()
+
+	comments := make(map[string]string)
+
+	for rows.Next() {
+		var schema, table, comment string
+
+		if err := rows.Scan(&schema, &table, &comment); err != nil {
+			return nil, err
+		}
+		comments[(schema + ":" + table)] = comment
+	}
+
+	return comments, rows.Err()
+}
+
 type DBFunction struct {
 	Name   string
 	Params []DBFuncParam
@@ -1,5 +1,7 @@
 package sdata
 
+import "database/sql"
+
 func GetTestDBInfo() *DBInfo {
 	columns := [][]DBColumn{
 		[]DBColumn{
@@ -40,7 +42,7 @@ func GetTestDBInfo() *DBInfo {
 			DBColumn{Schema: "public", Table: "purchases", Name: "id", Type: "bigint", NotNull: true, PrimaryKey: true, UniqueKey: true},
 			DBColumn{Schema: "public", Table: "purchases", Name: "customer_id", Type: "bigint", NotNull: false, PrimaryKey: false, UniqueKey: false, FKeySchema: "public", FKeyTable: "customers", FKeyCol: "id"},
 			DBColumn{Schema: "public", Table: "purchases", Name: "product_id", Type: "bigint", NotNull: false, PrimaryKey: false, UniqueKey: false, FKeySchema: "public", FKeyTable: "products", FKeyCol: "id"},
-			DBColumn{Schema: "public", Table: "purchases", Name: "sale_type", Type: "character varying", NotNull: false, PrimaryKey: false, UniqueKey: false},
+			DBColumn{Schema: "public", Table: "purchases", Name: "sale_type", Type: "sale_type", NotNull: false, PrimaryKey: false, UniqueKey: false, Enum: []string{"rented", "bought"}},
 			DBColumn{Schema: "public", Table: "purchases", Name: "quantity", Type: "integer", NotNull: false, PrimaryKey: false, UniqueKey: false},
 			DBColumn{Schema: "public", Table: "purchases", Name: "due_date", Type: "timestamp without time zone", NotNull: false, PrimaryKey: false, UniqueKey: false},
 			DBColumn{Schema: "public", Table: "purchases", Name: "returned", Type: "timestamp without time zone", NotNull: false, PrimaryKey: false, UniqueKey: false}},
@@ -77,8 +79,64 @@ func GetTestDBInfo() *DBInfo {
 		FKeyColumn: "id"},
 	}
 
-	di := NewDBInfo("", 110000, "public", "db", cols, nil, nil)
+	funcs := []DBFunction{{
+		Name:   "lower",
+		Params: []DBFuncParam{{ID: 1, Type: "text"}},
+	}}
+
+	di := NewDBInfo("", 110000, "public", "db", cols, funcs, nil)
 	di.VTables = vt
+
+	//nolint: errcheck
+	di.AddColumn(DBColumn{
+		Schema: "public",
+		Table:  "users",
+		Name:   "display_name",
+		Type:   "text",
+		SQL:    "users.full_name || ' <' || users.email || '>'",
+	})
+
+	di.setTableFunctions([]DBTableFunction{{
+		Schema:      "public",
+		Name:        "search_products",
+		TableSchema: "public",
+		Table:       "products",
+		Params: []DBFuncParam{
+			{ID: 1, Name: sql.NullString{String: "term", Valid: true}, Type: "text"},
+			{ID: 2, Name: sql.NullString{String: "max_price", Valid: true}, Type: "numeric"},
+		},
+	}, {
+		Schema: "public",
+		Name:   "top_buyers",
+		Params: []DBFuncParam{
+			{ID: 1, Name: sql.NullString{String: "since", Valid: true}, Type: "timestamp without time zone"},
+		},
+		Columns: []DBColumn{
+			{Schema: "public", Table: "top_buyers", Name: "user_id", Key: "user_id", Type: "bigint"},
+			{Schema: "public", Table: "top_buyers", Name: "total", Key: "total", Type: "numeric"},
+		},
+	}})
+
+	di.Procedures = []DBProcedure{{
+		Schema:      "public",
+		Name:        "set_price",
+		TableSchema: "public",
+		Table:       "products",
+		Params: []DBFuncParam{
+			{ID: 1, Name: sql.NullString{String: "product_id", Valid: true}, Type: "bigint"},
+			{ID: 2, Name: sql.NullString{String: "new_price", Valid: true}, Type: "numeric"},
+		},
+	}, {
+		Schema:     "public",
+		Name:       "product_count",
+		ReturnType: "bigint",
+	}, {
+		Schema: "public",
+		Name:   "close_account",
+		Params: []DBFuncParam{
+			{ID: 1, Name: sql.NullString{String: "user_id", Valid: true}, Type: "bigint"},
+		},
+	}}
 	return di
 }
 
@@ -7,22 +7,20 @@ import (
 	"github.com/chirino/graphql"
 	"github.com/chirino/graphql/resolvers"
 	"github.com/chirino/graphql/schema"
+	"github.com/dosco/graphjin/core/internal/qcode"
 	"github.com/dosco/graphjin/core/internal/sdata"
 )
 
-var typeMap map[string]string = map[string]string{
-	"smallint":         "Int",
-	"integer":          "Int",
-	"bigint":           "Int",
-	"smallserial":      "Int",
-	"serial":           "Int",
-	"bigserial":        "Int",
-	"decimal":          "Float",
-	"numeric":          "Float",
-	"real":             "Float",
-	"double precision": "Float",
-	"money":            "Float",
-	"boolean":          "Boolean",
+// scalarList is the list of scalar types added to the schema, other than the
+// ones built into GraphQL. The mapping from database types is in qcode
+var scalarList = []struct{ name, desc string }{
+	{"Cursor", "A cursor is an encoded string use for pagination"},
+	{"JSON", "A JSON value"},
+	{"UUID", "A UUID value, eg. 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11'"},
+	{"Date", "A date in the 'YYYY-MM-DD' format"},
+	{"DateTime", "A date and time in the RFC 3339 format, eg. '2021-01-09T16:37:01Z'"},
+	{"Decimal", "An arbitrary precision decimal number, use a string to keep the precision"},
+	{"BigInt", "A 64-bit integer, use a string to keep the precision"},
 }
 
 type expInfo struct {
@@ -109,9 +107,10 @@ type intro struct {
 	mutation     *schema.Object
 	subscription *schema.Object
 	exptNeeded   map[string]bool
+	qc           *qcode.Compiler
 }
 
-func (gj *GraphJin) initGraphQLEgine() error {
+func (gj *graphjin) initGraphQLEgine() error {
 	engine := graphql.New()
 	in := &intro{
 		Schema:       engine.Schema,
@@ -120,6 +119,7 @@ func (gj *GraphJin) initGraphQLEgine() error {
 		mutation:     &schema.Object{Name: "Mutation", Fields: schema.FieldList{}},
 		subscription: &schema.Object{Name: "Subscribe", Fields: schema.FieldList{}},
 		exptNeeded:   map[string]bool{},
+		qc:           gj.qc,
 	}
 
 	in.Types[in.query.Name] = in.query
@@ -140,16 +140,35 @@ func (gj *GraphJin) initGraphQLEgine() error {
 		},
 	}}
 
-	in.Types["Cursor"] = &schema.Scalar{
-		Name: "Cursor",
-		Desc: schema.NewDescription("A cursor is an encoded string use for pagination"),
+	for _, v := range scalarList {
+		in.Types[v.name] = &schema.Scalar{
+			Name: v.name,
+			Desc: schema.NewDescription(v.desc),
+		}
 	}
 
 	if err := in.addTables(); err != nil {
 		return err
 	}
+
+	for _, fn := range in.GetTableFunctions() {
+		// functions called by mutations are not queried
+		if !gj.qc.IsProcedure(fn.Name) {
+			in.addTableFunction(fn)
+		}
+	}
+
+	for _, f := range gj.conf.Functions {
+		if pr, ok := in.GetProcedure(f.Name); ok {
+			in.addProcedure(pr)
+		}
+	}
 	in.addExpressions()
 
+	for _, f := range gj.conf.RootFields {
+		in.addRootField(gj.rfmap[f.Name])
+	}
+
 	if err := in.ResolveTypes(); err != nil {
 		return err
 	}
@@ -159,6 +178,13 @@ func (gj *GraphJin) initGraphQLEgine() error {
 	return nil
 }
 
+// SchemaSDL function returns the GraphQL schema used for introspection in the
+// schema definition language (SDL). This includes the types for all the tables and
+// their aliases, the OrderDirection enum and the expression input types.
+func (g *GraphJin) SchemaSDL() string {
+	return g.engine().ge.Schema.String()
+}
+
 func revolverFunc(request *resolvers.ResolveRequest, next resolvers.Resolution) resolvers.Resolution {
 	resolver := resolvers.MetadataResolver.Resolve(request, next)
 	if resolver != nil {
@@ -222,6 +248,7 @@ func (in *intro) addTable(name string, ti sdata.DBTable) error {
 	// outputType
 	ot := &schema.Object{
 		Name: name + "Output", Fields: schema.FieldList{},
+		Desc: schema.NewDescription(ti.Comment),
 	}
 	in.Types[ot.Name] = ot
 
@@ -296,11 +323,15 @@ func (in *intro) addColumn(
 		return
 	}
 
-	colType, typeName := getGQLType(col)
+	colType, typeName := in.getGQLType(col)
+	in.addEnum(col)
+
+	colDesc := schema.NewDescription(col.Comment)
 
 	ot.Fields = append(ot.Fields, &schema.Field{
 		Name: colName,
 		Type: colType,
+		Desc: colDesc,
 	})
 
 	if col.PrimaryKey {
@@ -314,7 +345,8 @@ func (in *intro) addColumn(
 	// No functions on foreign key columns
 	if col.FKeyCol == "" {
 		// If it's a numeric type...
-		if typeName == "Float" || typeName == "Int" {
+		switch typeName {
+		case "Int", "BigInt", "Float", "Decimal":
 			for _, v := range numericFuncList {
 				desc := fmt.Sprintf(v.desc, colName)
 				ot.Fields = append(ot.Fields, &schema.Field{
@@ -339,10 +371,14 @@ func (in *intro) addColumn(
 
 	in.addArgs(name, ti, col, it, obt, expt, ot)
 
-	it.Fields = append(it.Fields, &schema.InputValue{
-		Name: colName,
-		Type: colType,
-	})
+	// computed columns cannot be set by mutations
+	if col.SQL == "" {
+		it.Fields = append(it.Fields, &schema.InputValue{
+			Name: colName,
+			Type: colType,
+			Desc: colDesc,
+		})
+	}
 	obt.Fields = append(obt.Fields, &schema.InputValue{
 		Name: colName,
 		Type: &schema.TypeName{Name: "OrderDirection"},
@@ -353,6 +389,7 @@ func (in *intro) addColumn(
 	expt.Fields = append(expt.Fields, &schema.InputValue{
 		Name: colName,
 		Type: &schema.TypeName{Name: typeName + "Expression"},
+		Desc: colDesc,
 	})
 }
 
@@ -364,54 +401,19 @@ func (in *intro) addArgs(
 	otName := &schema.TypeName{Name: ot.Name}
 	itName := &schema.TypeName{Name: it.Name}
 
+	// tables added for functions returning columns are
+	// only queried through the function
+	if ti.Type == "function" {
+		return
+	}
+
 	potName := &schema.List{OfType: &schema.NonNull{OfType: &schema.TypeName{Name: ot.Name}}}
 	pitName := &schema.List{OfType: &schema.NonNull{OfType: &schema.TypeName{Name: it.Name}}}
 
-	args := schema.InputValueList{
-		&schema.InputValue{
-			Desc: schema.NewDescription("Sort or order results. Use key 'asc' for ascending and 'desc' for descending"),
-			Name: "order_by",
-			Type: &schema.TypeName{Name: obt.Name},
-		},
-		&schema.InputValue{
-			Desc: schema.NewDescription("Filter results based on column values or values of columns in related tables"),
-			Name: "where",
-			Type: &schema.TypeName{Name: expt.Name},
-		},
-		&schema.InputValue{
-			Desc: schema.NewDescription("Limit the number of returned rows"),
-			Name: "limit",
-			Type: &schema.TypeName{Name: "Int"},
-		},
-		&schema.InputValue{
-			Desc: schema.NewDescription("Offset the number of returned rows (Not efficient for pagination, please use a cursor for that)"),
-			Name: "offset",
-			Type: &schema.TypeName{Name: "Int"},
-		},
-		&schema.InputValue{
-			Desc: schema.NewDescription("Number of rows to return from the top. Combine with 'after' or 'before' arguments for cursor pagination"),
-			Name: "first",
-			Type: &schema.TypeName{Name: "Int"},
-		},
-		&schema.InputValue{
-			Desc: schema.NewDescription("Number of rows to return from the bottom. Combine with 'after' or 'before' arguments for cursor pagination"),
-			Name: "last",
-			Type: &schema.TypeName{Name: "Int"},
-		},
-		&schema.InputValue{
-			Desc: schema.NewDescription("Pass the cursor to this argument for backward pagination"),
-			Name: "before",
-			Type: &schema.TypeName{Name: "Cursor"},
-		},
-		&schema.InputValue{
-			Desc: schema.NewDescription("Pass the cursor to this argument for forward pagination"),
-			Name: "after",
-			Type: &schema.TypeName{Name: "Cursor"},
-		},
-	}
+	args := listArgs(obt.Name, expt.Name)
 
 	if ti.PrimaryCol.Name != "" {
-		colType, _ := getGQLType(col)
+		colType, _ := in.getGQLType(col)
 		args = append(args, &schema.InputValue{
 			Desc: schema.NewDescription("Finds the record by the primary key"),
 			Name: "id",
@@ -428,26 +430,26 @@ func (in *intro) addArgs(
 	}
 
 	in.query.Fields = append(in.query.Fields, &schema.Field{
-		//Desc: schema.NewDescription(""),
+		Desc: schema.NewDescription(ti.Comment),
 		Name: name,
 		Type: otName,
 		Args: args,
 	})
 	in.query.Fields = append(in.query.Fields, &schema.Field{
-		//Desc: schema.NewDescription(""),
+		Desc: schema.NewDescription(ti.Comment),
 		Name: name,
 		Type: potName,
 		Args: args,
 	})
 
 	in.subscription.Fields = append(in.subscription.Fields, &schema.Field{
-		//Desc: schema.NewDescription(""),
+		Desc: schema.NewDescription(ti.Comment),
 		Name: name,
 		Type: otName,
 		Args: args,
 	})
 	in.subscription.Fields = append(in.subscription.Fields, &schema.Field{
-		//Desc: schema.NewDescription(""),
+		Desc: schema.NewDescription(ti.Comment),
 		Name: name,
 		Type: potName,
 		Args: args,
@@ -482,6 +484,153 @@ func (in *intro) addArgs(
 	})
 }
 
+// listArgs returns the arguments used to filter, sort and
+// paginate the rows returned by a selector
+func listArgs(obtName, exptName string) schema.InputValueList {
+	return schema.InputValueList{
+		&schema.InputValue{
+			Desc: schema.NewDescription("Sort or order results. Use key 'asc' for ascending and 'desc' for descending"),
+			Name: "order_by",
+			Type: &schema.TypeName{Name: obtName},
+		},
+		&schema.InputValue{
+			Desc: schema.NewDescription("Filter results based on column values or values of columns in related tables"),
+			Name: "where",
+			Type: &schema.TypeName{Name: exptName},
+		},
+		&schema.InputValue{
+			Desc: schema.NewDescription("Limit the number of returned rows"),
+			Name: "limit",
+			Type: &schema.TypeName{Name: "Int"},
+		},
+		&schema.InputValue{
+			Desc: schema.NewDescription("Offset the number of returned rows (Not efficient for pagination, please use a cursor for that)"),
+			Name: "offset",
+			Type: &schema.TypeName{Name: "Int"},
+		},
+		&schema.InputValue{
+			Desc: schema.NewDescription("Number of rows to return from the top. Combine with 'after' or 'before' arguments for cursor pagination"),
+			Name: "first",
+			Type: &schema.TypeName{Name: "Int"},
+		},
+		&schema.InputValue{
+			Desc: schema.NewDescription("Number of rows to return from the bottom. Combine with 'after' or 'before' arguments for cursor pagination"),
+			Name: "last",
+			Type: &schema.TypeName{Name: "Int"},
+		},
+		&schema.InputValue{
+			Desc: schema.NewDescription("Pass the cursor to this argument for backward pagination"),
+			Name: "before",
+			Type: &schema.TypeName{Name: "Cursor"},
+		},
+		&schema.InputValue{
+			Desc: schema.NewDescription("Pass the cursor to this argument for forward pagination"),
+			Name: "after",
+			Type: &schema.TypeName{Name: "Cursor"},
+		},
+	}
+}
+
+// addTableFunction adds a query field for a database function returning
+// table rows, the parameters of the function are added as arguments
+func (in *intro) addTableFunction(fn sdata.DBTableFunction) {
+	ti, err := in.Find(fn.ResultTable())
+	if err != nil || ti.Blocked || len(ti.Columns) == 0 {
+		return
+	}
+
+	args := listArgs(ti.Name+"OrderBy", ti.Name+"Expression")
+	args = in.addParams(args, fn.Params)
+
+	otName := &schema.TypeName{Name: ti.Name + "Output"}
+
+	in.query.Fields = append(in.query.Fields, &schema.Field{
+		Desc: schema.NewDescription(ti.Comment),
+		Name: fn.Name,
+		Type: &schema.List{OfType: &schema.NonNull{OfType: otName}},
+		Args: args,
+	})
+}
+
+// addProcedure adds a mutation field for a database function or stored
+// procedure, the parameters of the function are added as arguments
+func (in *intro) addProcedure(pr sdata.DBProcedure) {
+	var typ schema.Type
+	args := schema.InputValueList{}
+
+	switch {
+	case pr.Table != "":
+		ti, err := in.Find(pr.TableSchema, pr.Table)
+		if err != nil || ti.Blocked || len(ti.Columns) == 0 {
+			return
+		}
+		typ = &schema.TypeName{Name: ti.Name + "Output"}
+
+		if pr.SetOf {
+			typ = &schema.List{OfType: &schema.NonNull{OfType: typ}}
+			args = listArgs(ti.Name+"OrderBy", ti.Name+"Expression")
+		}
+
+	case pr.ReturnType != "":
+		typ, _ = in.getGQLType(sdata.DBColumn{Type: pr.ReturnType})
+
+	default:
+		// functions returning nothing return null
+		typ = &schema.TypeName{Name: "JSON"}
+	}
+
+	in.mutation.Fields = append(in.mutation.Fields, &schema.Field{
+		Name: pr.Name,
+		Type: typ,
+		Args: in.addParams(args, pr.Params),
+	})
+}
+
+// addParams adds the parameters of a function to the arguments
+func (in *intro) addParams(args schema.InputValueList, params []sdata.DBFuncParam) schema.InputValueList {
+	for _, p := range params {
+		col := sdata.DBColumn{Type: p.Type}
+
+		args = append(args, &schema.InputValue{
+			Desc: schema.NewDescription(fmt.Sprintf("Parameter '%s' of the function", p.Name.String)),
+			Name: p.Name.String,
+			Type: &schema.TypeName{Name: in.qc.ScalarType(col)},
+		})
+	}
+	return args
+}
+
+// addRootField adds a root field resolved by a function
+// to the query or mutation type
+func (in *intro) addRootField(rf rootField) {
+	f := &schema.Field{
+		Name: rf.Name,
+		Type: rf.typ,
+		Args: schema.InputValueList{},
+	}
+
+	if rf.Desc != "" {
+		f.Desc = schema.NewDescription(rf.Desc)
+	}
+
+	for _, a := range rf.Args {
+		iv := &schema.InputValue{
+			Name: a.Name,
+			Type: rf.args[a.Name],
+		}
+		if a.Desc != "" {
+			iv.Desc = schema.NewDescription(a.Desc)
+		}
+		f.Args = append(f.Args, iv)
+	}
+
+	if rf.Mutation {
+		in.mutation.Fields = append(in.mutation.Fields, f)
+	} else {
+		in.query.Fields = append(in.query.Fields, f)
+	}
+}
+
 func (in *intro) addExpressions() {
 	// scalarExpressionTypesNeeded
 	for typeName := range in.exptNeeded {
@@ -511,19 +660,22 @@ func (in *intro) addExpressions() {
 	}
 }
 
-func getGQLType(col sdata.DBColumn) (schema.Type, string) {
+func (in *intro) getGQLType(col sdata.DBColumn) (schema.Type, string) {
 	var typeName string
 	var ok bool
 
-	k := strings.ToLower(col.Type)
-	if i := strings.IndexAny(k, "(["); i != -1 {
-		k = k[:i]
-	}
-
-	if col.PrimaryKey {
+	switch {
+	case col.PrimaryKey:
 		typeName = "ID"
-	} else if typeName, ok = typeMap[k]; !ok {
-		typeName = "String"
+
+	case len(col.Enum) != 0:
+		if typeName, ok = enumTypeName(col); !ok {
+			typeName = "String"
+		}
+
+	default:
+		typeName = in.qc.ScalarType(col)
+		in.addScalar(typeName)
 	}
 
 	var t schema.Type = &schema.TypeName{Name: typeName}
@@ -536,6 +688,79 @@ func getGQLType(col sdata.DBColumn) (schema.Type, string) {
 	return t, typeName
 }
 
+// addScalar adds the custom scalar types used in the type map config
+func (in *intro) addScalar(name string) {
+	switch name {
+	case "Int", "Float", "String", "Boolean", "ID":
+		return
+	}
+
+	if _, ok := in.Types[name]; !ok {
+		in.Types[name] = &schema.Scalar{Name: name}
+	}
+}
+
+// addEnum adds the enum type used by an enum column
+func (in *intro) addEnum(col sdata.DBColumn) {
+	name, ok := enumTypeName(col)
+	if !ok {
+		return
+	}
+
+	if _, ok := in.Types[name]; ok {
+		return
+	}
+
+	et := &schema.Enum{Name: name}
+	for _, v := range col.Enum {
+		et.Values = append(et.Values, &schema.EnumValue{Name: v})
+	}
+	in.Types[name] = et
+}
+
+// enumTypeName returns the name of the enum type of an enum column, on Postgres
+// it's the name of the type and on MySQL the table and column name. Enums with
+// values that are not valid GraphQL names are used as strings
+func enumTypeName(col sdata.DBColumn) (string, bool) {
+	if len(col.Enum) == 0 {
+		return "", false
+	}
+
+	for _, v := range col.Enum {
+		if !isGQLName(v) || v == "true" || v == "false" || v == "null" {
+			return "", false
+		}
+	}
+
+	name := strings.TrimSuffix(col.Type, "[]")
+	if i := strings.LastIndexByte(name, '.'); i != -1 {
+		name = name[i+1:]
+	}
+	name = strings.Trim(name, `"`)
+
+	if strings.EqualFold(name, "enum") {
+		name = col.Table + "_" + col.Name
+	}
+
+	return name, isGQLName(name)
+}
+
+func isGQLName(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i, c := range s {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i != 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 func getRelName(colName string) string {
 	cn := strings.ToLower(colName)
 
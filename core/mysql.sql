@@ -29,7 +29,7 @@ CREATE TABLE products (
   name VARCHAR(255) ,
   description VARCHAR(255),
   tags  VARCHAR(255),  
-  price FLOAT(7,1),
+  price FLOAT(7,1) COMMENT 'Price in dollars',
   owner_id BIGINT,
   category_ids VARCHAR(255) NOT NULL ,
   created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -38,12 +38,13 @@ CREATE TABLE products (
   CHECK (length(name) > 1 AND length(name) < 50),
   FOREIGN KEY (owner_id) REFERENCES users(id),
   FULLTEXT(name,description)
-);
+) COMMENT='Products for sale';
 
 CREATE TABLE purchases (
   id BIGINT NOT NULL PRIMARY KEY,
   customer_id BIGINT,
   product_id BIGINT,
+  sale_type ENUM('rented', 'bought') NOT NULL DEFAULT 'bought',
   quantity integer,
   returned_at TIMESTAMP,
   created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -88,6 +89,10 @@ AS (
       id > 50
 );
 
+CREATE PROCEDURE 
+  set_product_price(IN product_id BIGINT, IN new_price FLOAT(7,1))
+  UPDATE products SET price = new_price WHERE id = product_id;
+
 -- CREATE TABLE chats (
 --   id BIGINT NOT NULL PRIMARY KEY,
 --   body VARCHAR(255),
@@ -38,10 +38,16 @@ CREATE TABLE products (
   updated_at TIMESTAMPTZ
 );
 
+COMMENT ON TABLE products IS 'Products for sale';
+COMMENT ON COLUMN products.price IS 'Price in dollars';
+
+CREATE TYPE sale_type AS ENUM ('rented', 'bought');
+
 CREATE TABLE purchases (
   id BIGSERIAL PRIMARY KEY,
   customer_id BIGINT REFERENCES users(id),
   product_id BIGINT REFERENCES products(id),
+  sale_type sale_type NOT NULL DEFAULT 'bought',
   quantity integer,
   returned_at TIMESTAMPTZ,
   created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
@@ -79,6 +85,18 @@ AS (
       id > 50
 );
 
+CREATE FUNCTION 
+  products_in_price_range(min_price NUMERIC, max_price NUMERIC) 
+RETURNS SETOF products AS $$
+  SELECT * FROM products WHERE price BETWEEN min_price AND max_price
+$$ LANGUAGE SQL STABLE;
+
+CREATE FUNCTION 
+  set_product_price(product_id BIGINT, new_price NUMERIC) 
+RETURNS products AS $$
+  UPDATE products SET price = new_price WHERE id = product_id RETURNING *
+$$ LANGUAGE SQL;
+
 -- CREATE TABLE chats (
 --   id BIGSERIAL PRIMARY KEY,
 --   body TEXT,
@@ -2,6 +2,8 @@ package core
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"strings"
@@ -15,6 +17,7 @@ type cquery struct {
 	sync.Once
 	q       rquery
 	stmts   []stmt
+	mstmts  []stmt
 	st      stmt
 	roleArg bool
 }
@@ -27,7 +30,7 @@ type rquery struct {
 }
 
 // nolint: errcheck
-func (gj *GraphJin) prepareRoleStmt() error {
+func (gj *graphjin) prepareRoleStmt() error {
 	if !gj.abacEnabled {
 		return nil
 	}
@@ -64,34 +67,49 @@ func (gj *GraphJin) prepareRoleStmt() error {
 	return nil
 }
 
-func (gj *GraphJin) initAllowList() error {
+func (gj *graphjin) initAllowList() error {
 	var err error
 
 	if gj.conf.DisableAllowList {
 		return nil
 	}
 
-	gj.allowList, err = allow.New(gj.conf.AllowListFile, allow.Config{
-		Log: gj.log,
-	})
+	// the allow list of the previous engine is kept
+	// when reloading since it has a save goroutine
+	if gj.allowList == nil {
+		gj.allowList, err = allow.New(gj.conf.AllowListFile, allow.Config{
+			Log: gj.log,
+		})
 
-	if err != nil {
-		return fmt.Errorf("failed to initialize allow list: %w", err)
+		if err != nil {
+			return fmt.Errorf("failed to initialize allow list: %w", err)
+		}
 	}
 
 	gj.queries = make(map[string]*cquery)
+	gj.hashes = make(map[string]string)
 
 	list, err := gj.allowList.Load()
 	if err != nil {
 		return err
 	}
 
+	hm, err := gj.allowList.Hashes()
+	if err != nil {
+		return err
+	}
+	qm := make(map[string]string, len(list))
+
 	for _, v := range list {
 		if v.Query == "" {
 			continue
 		}
 
-		qt, _ := qcode.GetQType(v.Query)
+		qt, _ := qcode.GetQType(v.Query, "")
+
+		h := sha256.Sum256([]byte(v.Query))
+		gj.hashes[hex.EncodeToString(h[:])] = v.Query
+		qm[v.Name] = v.Query
 
 		q := rquery{
 			op:    qt,
@@ -112,5 +130,13 @@ func (gj *GraphJin) initAllowList() error {
 		}
 	}
 
+	// clients hash the query text they send which can differ from
+	// the saved query so the hashes saved with the queries are added
+	for h, name := range hm {
+		if q, ok := qm[name]; ok {
+			gj.hashes[h] = q
+		}
+	}
+
 	return nil
 }
@@ -3,9 +3,14 @@ package core_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/dosco/graphjin/core"
 )
@@ -36,6 +41,184 @@ func Example_query() {
 	// Output: {"products": [{"id": 1, "owner": {"id": 1, "fullName": "User 1"}}, {"id": 2, "owner": {"id": 2, "fullName": "User 2"}}, {"id": 3, "owner": {"id": 3, "fullName": "User 3"}}]}
 }
 
+func Example_queryWithMultipleOperations() {
+	gql := `
+	query getProducts {
+		products(limit: 2) {
+			id
+		}
+	}
+
+	query getUsers {
+		users(limit: 2) {
+			id
+		}
+	}`
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := gj.GraphQLEx(context.Background(), gql, "getUsers", nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(string(res.Data))
+	}
+	// Output: {"users": [{"id": 1}, {"id": 2}]}
+}
+
+func Example_queryBatch() {
+	reqs := []core.BatchReq{
+		{Query: `query { products(limit: 2) { id } }`},
+		{Query: `query { users(limit: 2) { id } }`},
+	}
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, res := range gj.GraphQLBatch(context.Background(), reqs, nil) {
+		if len(res.Errors) != 0 {
+			fmt.Println(res.Errors[0].Message)
+		} else {
+			fmt.Println(string(res.Data))
+		}
+	}
+	// Output:
+	// {"products": [{"id": 1}, {"id": 2}]}
+	// {"users": [{"id": 1}, {"id": 2}]}
+}
+
+func Example_queryWithTracing() {
+	gql := `query {
+		products(limit: 1) {
+			id
+			owner {
+				id
+			}
+		}
+	}`
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	rc := core.ReqConfig{EnableTracing: true}
+	res, err := gj.GraphQL(context.Background(), gql, nil, &rc)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, r := range res.Extensions.Tracing.Execution.Resolvers {
+		fmt.Println(r.Path, r.ParentType, r.ReturnType)
+	}
+	// Output:
+	// [products] Query [products]
+	// [products owner] products users
+}
+
+func Example_queryWithTimeout() {
+	gql := `query {
+		products(limit: 2) {
+			id
+		}
+	}`
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true, QueryTimeout: time.Nanosecond}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if errors.Is(err, core.ErrTimeout) {
+		fmt.Println(res.Errors[0].Extensions.Code)
+	} else {
+		fmt.Println(err)
+	}
+	// Output: QUERY_TIMEOUT
+}
+
+func Example_queryWithTimeoutInTx() {
+	gql := `query {
+		products(limit: 2) {
+			id
+		}
+	}`
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true, QueryTimeout: time.Minute}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := context.Background()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		panic(err)
+	}
+	defer tx.Rollback() //nolint: errcheck
+
+	if _, err := gj.GraphQLTx(ctx, tx, gql, nil, nil); err != nil {
+		panic(err)
+	}
+
+	// the statement timeout of the caller's transaction is unchanged
+	var st string
+	if err := tx.QueryRow(`SHOW statement_timeout`).Scan(&st); err != nil {
+		panic(err)
+	}
+	fmt.Println(st)
+	// Output: 0
+}
+
+type blockUsersHook struct {
+	core.NopHooks
+}
+
+func (blockUsersHook) OnCompile(c context.Context, q *core.QueryInfo) error {
+	for _, sel := range q.Selects {
+		if sel.Table == "users" {
+			return fmt.Errorf("%w: users cannot be queried", core.ErrUnauthorized)
+		}
+	}
+	return nil
+}
+
+func Example_queryWithHooks() {
+	gql := `query {
+		products(limit: 2) {
+			id
+			owner {
+				id
+			}
+		}
+	}`
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true, Hooks: blockUsersHook{}}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		fmt.Println(res.Errors[0].Message, res.Errors[0].Extensions.Code)
+	} else {
+		fmt.Println(string(res.Data))
+	}
+	// Output: unauthorized: users cannot be queried UNAUTHORIZED
+}
+
 func Example_queryWithUser() {
 	gql := `query {
 		products(where: { owner_id: { eq: $user_id } }) {
@@ -464,6 +647,31 @@ func Example_queryWithAggregation() {
 	// Output: {"products": [{"count_id": 100}]}
 }
 
+func Example_queryWithGroupByAndHaving() {
+	gql := `query {
+		products(
+			where: { id: { lteq: 100 } },
+			group_by: { date_trunc: { field: created_at, unit: day } },
+			having: { count_id: { gt: 5 } }) {
+			count_id
+		}
+	}`
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(string(res.Data))
+	}
+	// Output: {"products": [{"count_id": 100}]}
+}
+
 func Example_queryWithAggregationBlockedColumn() {
 	gql := `query {
 		products {
@@ -862,6 +1070,698 @@ func Example_queryWithRemoteAPIJoin() {
 	// Output: {"users": [{"email": "user1@test.com", "payments":[{"desc":"Payment 1 for payment_id_1001"},{"desc": "Payment 2 for payment_id_1001"}]}, {"email": "user2@test.com", "payments":[{"desc":"Payment 1 for payment_id_1002"},{"desc": "Payment 2 for payment_id_1002"}]}]}
 }
 
+func Example_queryWithBatchRemoteAPIJoin() {
+	gql := `query {
+		users {
+			email
+			payments {
+				desc
+			}
+		}
+	}`
+
+	// fake remote api service that returns the payments for a list of ids
+	go func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/payments", func(w http.ResponseWriter, r *http.Request) {
+			var items []string
+			for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+				items = append(items, fmt.Sprintf(`{"id":"%s","desc":"Payment for %s"}`, id, id))
+			}
+			fmt.Fprintf(w, "[%s]", strings.Join(items, ","))
+		})
+		log.Fatal(http.ListenAndServe(":12346", mux))
+	}()
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true, DefaultLimit: 2}
+	conf.Resolvers = []core.ResolverConfig{{
+		Name:   "payments",
+		Type:   "remote_api",
+		Table:  "users",
+		Column: "stripe_id",
+		Props: core.ResolverProps{
+			"url":            "http://localhost:12346/payments?ids=$ids",
+			"batch":          true,
+			"batch_id_field": "id",
+		},
+	}}
+
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(string(res.Data))
+	}
+	// Output: {"users": [{"email": "user1@test.com", "payments":{"desc":"Payment for payment_id_1001"}}, {"email": "user2@test.com", "payments":{"desc":"Payment for payment_id_1002"}}]}
+}
+
+func Example_queryWithRemoteAPITracing() {
+	gql := `query {
+		users {
+			email
+			payments {
+				desc
+			}
+		}
+	}`
+
+	// fake remote api service
+	go func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/payments/", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"data":[{"desc":"Payment for %s"}]}`, r.URL.Path[10:])
+		})
+		log.Fatal(http.ListenAndServe(":12350", mux))
+	}()
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true, DefaultLimit: 2}
+	conf.Resolvers = []core.ResolverConfig{{
+		Name:      "payments",
+		Type:      "remote_api",
+		Table:     "users",
+		Column:    "stripe_id",
+		StripPath: "data",
+		Props:     core.ResolverProps{"url": "http://localhost:12350/payments/$id"},
+	}}
+
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	rc := core.ReqConfig{EnableTracing: true}
+	res, err := gj.GraphQL(context.Background(), gql, nil, &rc)
+	if err != nil {
+		panic(err)
+	}
+
+	// the remote selector is traced once for each user
+	for _, r := range res.Extensions.Tracing.Execution.Resolvers {
+		if r.FieldName == "payments" {
+			fmt.Println(r.Path)
+		}
+	}
+	// Output:
+	// [users 0 payments]
+	// [users 1 payments]
+}
+
+func Example_queryWithRemoteAPIJoinFailure() {
+	gql := `query {
+		users {
+			email
+			payments {
+				desc
+			}
+		}
+	}`
+
+	// fake remote api service that is down
+	go func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/payments/", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		log.Fatal(http.ListenAndServe(":12347", mux))
+	}()
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true, DefaultLimit: 1}
+	conf.Resolvers = []core.ResolverConfig{{
+		Name:   "payments",
+		Type:   "remote_api",
+		Table:  "users",
+		Column: "stripe_id",
+		Props: core.ResolverProps{
+			"url":           "http://localhost:12347/payments/$id",
+			"timeout":       "1s",
+			"retries":       1,
+			"retry_backoff": "10ms",
+		},
+	}}
+
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(string(res.Data))
+		fmt.Println(res.Errors[0].Message, res.Errors[0].Extensions.Code)
+	}
+	// Output:
+	// {"users": [{"email": "user1@test.com", "payments":null}]}
+	// payments: server responded with a 503 REMOTE_API_ERROR
+}
+
+func Example_queryWithRemoteGraphQLJoin() {
+	gql := `query {
+		users {
+			email
+			payments {
+				desc
+				card: payment_card {
+					last4
+				}
+			}
+		}
+	}`
+
+	// fake remote graphql service
+	go func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+			var req struct {
+				Variables struct{ ID string }
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintf(w, `{"data":{"payment":{"desc":"Payment for %s","card":{"last4":"4242"}}}}`,
+				req.Variables.ID)
+		})
+		log.Fatal(http.ListenAndServe(":12348", mux))
+	}()
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true, DefaultLimit: 2}
+	conf.Resolvers = []core.ResolverConfig{{
+		Name:   "payments",
+		Type:   "graphql",
+		Table:  "users",
+		Column: "stripe_id",
+		Props: core.ResolverProps{
+			"url":   "http://localhost:12348/graphql",
+			"field": "payment",
+		},
+	}}
+
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(string(res.Data))
+	}
+	// Output: {"users": [{"email": "user1@test.com", "payments":{"desc":"Payment for payment_id_1001","card":{"last4":"4242"}}}, {"email": "user2@test.com", "payments":{"desc":"Payment for payment_id_1002","card":{"last4":"4242"}}}]}
+}
+
+func Example_queryWithRemoteGraphQLVariables() {
+	gql := `query {
+		users {
+			email
+			payments {
+				card(type: $type) {
+					last4
+				}
+			}
+		}
+	}`
+
+	vars := json.RawMessage(`{ "type": "visa" }`)
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true}
+	conf.Resolvers = []core.ResolverConfig{{
+		Name:   "payments",
+		Type:   "graphql",
+		Table:  "users",
+		Column: "stripe_id",
+		Props: core.ResolverProps{
+			"url":   "http://localhost:12348/graphql",
+			"field": "payment",
+		},
+	}}
+
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	// variables can't be used in the arguments of remote fields
+	res, err := gj.GraphQL(context.Background(), gql, vars, nil)
+	if err != nil {
+		fmt.Println(res.Errors[0].Extensions.Code)
+	} else {
+		fmt.Println(string(res.Data))
+	}
+	// Output: GRAPHQL_VALIDATION_FAILED
+}
+
+func Example_queryWithRootFields() {
+	gql := `query {
+		products(limit: 2) {
+			id
+		}
+		weather(city: $city) {
+			temp
+		}
+	}`
+
+	vars := json.RawMessage(`{ "city": "Paris" }`)
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true}
+	conf.RootFields = []core.RootField{{
+		Name: "weather",
+		Type: "JSON",
+		Args: []core.RootFieldArg{{Name: "city", Type: "String!"}},
+		Fn: func(c context.Context, args json.RawMessage) ([]byte, error) {
+			var a struct{ City string }
+			if err := json.Unmarshal(args, &a); err != nil {
+				return nil, err
+			}
+			return []byte(fmt.Sprintf(`{"city":"%s","temp":20,"wind":5}`, a.City)), nil
+		},
+	}}
+
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, vars, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(string(res.Data))
+	}
+	// Output: {"products": [{"id": 1}, {"id": 2}], "weather": {"temp":20}}
+}
+
+func Example_explainQuery() {
+	gql := `query {
+		products(where: { id: { eq: $id } }) {
+			id
+			name
+		}
+	}`
+
+	vars := json.RawMessage(`{ "id": 2 }`)
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	ex, err := gj.Explain(context.Background(), gql, vars)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(ex.Role, ex.Params[0].Name, ex.Params[0].Value, len(ex.Plan) != 0)
+	}
+	// Output: anon id 2 true
+}
+
+func Example_explainQueryWithOperationName() {
+	gql := `
+	query getUsers {
+		users(where: { id: { eq: $id } }) {
+			id
+		}
+	}
+
+	query getProducts {
+		products(where: { price: { gt: $price } }) {
+			id
+		}
+	}`
+
+	vars := json.RawMessage(`{ "price": 10 }`)
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	ex, err := gj.ExplainEx(context.Background(), gql, "getProducts", vars)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(ex.Params[0].Name, ex.Params[0].Value)
+	}
+	// Output: price 10
+}
+
+func Example_reload() {
+	gql := `query {
+		reload_items {
+			id
+			name
+		}
+	}`
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = db.Exec(`CREATE TABLE reload_items (id int PRIMARY KEY, name text)`)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Exec(`DROP TABLE reload_items`) //nolint: errcheck
+
+	_, err = db.Exec(`INSERT INTO reload_items (id, name) VALUES (1, 'Item 1')`)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = gj.GraphQL(context.Background(), gql, nil, nil)
+	fmt.Println(err != nil)
+
+	if err := gj.Reload(); err != nil {
+		panic(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(string(res.Data))
+	}
+	// Output:
+	// true
+	// {"reload_items": [{"id": 1, "name": "Item 1"}]}
+}
+
+func Example_reloadWithResolversAndAllowList() {
+	gql := `query {
+		users(limit: 1) {
+			email
+		}
+	}`
+
+	dir, err := ioutil.TempDir("", "graphjin")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	conf := &core.Config{DBType: dbType, AllowListFile: dir + "/allow.list"}
+	conf.Resolvers = []core.ResolverConfig{{
+		Name:      "payments",
+		Type:      "remote_api",
+		Table:     "users",
+		Column:    "stripe_id",
+		StripPath: "data",
+		Props:     core.ResolverProps{"url": "http://localhost:12345/payments/$id"},
+	}}
+
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	// the config is reused by each reload
+	for i := 0; i < 2; i++ {
+		if err := gj.Reload(); err != nil {
+			panic(err)
+		}
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(string(res.Data))
+	}
+	// Output: {"users": [{"email": "user1@test.com"}]}
+}
+
+func Example_schemaSnapshot() {
+	gql := `query {
+		products(limit: 2) {
+			id
+			name
+		}
+	}`
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	f, err := ioutil.TempFile("", "schema*.json")
+	if err != nil {
+		panic(err)
+	}
+	defer os.Remove(f.Name())
+
+	if err := gj.WriteSchemaSnapshot(f); err != nil {
+		panic(err)
+	}
+	f.Close()
+
+	conf = &core.Config{DBType: dbType, DisableAllowList: true, SchemaSnapshot: f.Name()}
+	gj, err = core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(string(res.Data))
+	}
+	// Output: {"products": [{"id": 1, "name": "Product 1"}, {"id": 2, "name": "Product 2"}]}
+}
+
+func Example_schemaSDL() {
+	conf := &core.Config{DBType: dbType, DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	sdl := gj.SchemaSDL()
+
+	fmt.Println(strings.Contains(sdl, "products"),
+		strings.Contains(sdl, "OrderDirection"))
+	// Output: true true
+}
+
+func Example_introspectionWithComments() {
+	gql := `query IntrospectionQuery {
+		__type(name: "productsOutput") {
+			description
+			fields {
+				name
+				description
+			}
+		}
+	}`
+
+	conf := &core.Config{
+		DBType:           dbType,
+		DisableAllowList: true,
+		Tables: []core.Table{{
+			Name:    "products",
+			Columns: []core.Column{{Name: "name", Description: "Name of the product"}},
+		}},
+	}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var v struct {
+		Type struct {
+			Description string
+			Fields      []struct{ Name, Description string }
+		} `json:"__type"`
+	}
+
+	if err := json.Unmarshal(res.Data, &v); err != nil {
+		panic(err)
+	}
+
+	desc := make(map[string]string)
+	for _, f := range v.Type.Fields {
+		desc[f.Name] = f.Description
+	}
+
+	fmt.Println(v.Type.Description)
+	fmt.Println("name -", desc["name"])
+	fmt.Println("price -", desc["price"])
+	// Output:
+	// Products for sale
+	// name - Name of the product
+	// price - Price in dollars
+}
+
+func Example_queryWithEnumValues() {
+	conf := &core.Config{DBType: dbType, DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	gql := `query {
+		purchases(where: { sale_type: { eq: sold } }) {
+			id
+		}
+	}`
+
+	_, err = gj.GraphQL(context.Background(), gql, nil, nil)
+	fmt.Println(errors.Is(err, core.ErrValidation))
+
+	gql = `query {
+		purchases(where: { sale_type: { eq: bought } }, order_by: { id: asc }, limit: 2) {
+			id
+			sale_type
+		}
+	}`
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(string(res.Data))
+	}
+	// Output:
+	// true
+	// {"purchases": [{"id": 1, "sale_type": "bought"}, {"id": 2, "sale_type": "bought"}]}
+}
+
+func Example_queryWithScalarVariables() {
+	conf := &core.Config{DBType: dbType, DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	gql := `query {
+		products(where: { price: { gt: $price } }, order_by: { id: asc }, limit: 2) {
+			id
+		}
+	}`
+
+	vars := json.RawMessage(`{ "price": "ten" }`)
+
+	_, err = gj.GraphQL(context.Background(), gql, vars, nil)
+	fmt.Println(errors.Is(err, core.ErrValidation))
+
+	vars = json.RawMessage(`{ "price": "10.50" }`)
+
+	res, err := gj.GraphQL(context.Background(), gql, vars, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(string(res.Data))
+	}
+	// Output:
+	// true
+	// {"products": [{"id": 1}, {"id": 2}]}
+}
+
+func Example_queryWithComputedColumns() {
+	gql := `query {
+		users(where: { display_name: { like: "User 1%" } }, order_by: { display_name: desc }, limit: 2) {
+			id
+			display_name
+		}
+	}`
+
+	conf := &core.Config{
+		DBType:           dbType,
+		DisableAllowList: true,
+		Tables: []core.Table{{
+			Name: "users",
+			Columns: []core.Column{{
+				Name: "display_name",
+				SQL:  "CONCAT(users.full_name, ' <', users.email, '>')",
+			}},
+		}},
+	}
+
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(string(res.Data))
+	}
+	// Output: {"users": [{"id": 19, "display_name": "User 19 <user19@test.com>"}, {"id": 18, "display_name": "User 18 <user18@test.com>"}]}
+}
+
+func Example_queryWithRemoteAPICachedPerHeader() {
+	gql := `query {
+		users {
+			email
+			payments {
+				desc
+			}
+		}
+	}`
+
+	// fake remote api service that returns the payments of the
+	// user set in the authorization header
+	go func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/payments/", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"desc":"Payment for %s"}`, r.Header.Get("Authorization"))
+		})
+		log.Fatal(http.ListenAndServe(":12349", mux))
+	}()
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true, DefaultLimit: 1}
+	conf.Resolvers = []core.ResolverConfig{{
+		Name:   "payments",
+		Type:   "remote_api",
+		Table:  "users",
+		Column: "stripe_id",
+		Props: core.ResolverProps{
+			"url":          "http://localhost:12349/payments/$id",
+			"cache_ttl":    "1m",
+			"pass_headers": []string{"Authorization"},
+		},
+	}}
+
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, v := range []string{"user1", "user2"} {
+		rc := core.ReqConfig{Header: http.Header{"Authorization": []string{v}}}
+
+		res, err := gj.GraphQL(context.Background(), gql, nil, &rc)
+		if err != nil {
+			fmt.Println(err)
+		} else {
+			fmt.Println(string(res.Data))
+		}
+	}
+	// Output:
+	// {"users": [{"email": "user1@test.com", "payments":{"desc":"Payment for user1"}}]}
+	// {"users": [{"email": "user1@test.com", "payments":{"desc":"Payment for user2"}}]}
+}
+
 func Example_queryWithCursorPagination() {
 	gql := `query {
 		Products(
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/dosco/graphjin/core"
@@ -12,6 +13,61 @@ import (
 
 func TestQuery(t *testing.T) {
 	t.Run("queryWithVariableLimit", queryWithVariableLimit)
+	t.Run("queryWithTableFunction", queryWithTableFunction)
+	t.Run("queryWhileReloading", queryWhileReloading)
+}
+
+// queryWhileReloading runs queries while GraphJin is reloaded,
+// run with -race to check the engines don't share any state
+func queryWhileReloading(t *testing.T) {
+	gql := `query {
+		products(limit: 2) {
+			id
+		}
+	}`
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true}
+	conf.Resolvers = []core.ResolverConfig{{
+		Name:   "payments",
+		Type:   "remote_api",
+		Table:  "users",
+		Column: "stripe_id",
+		Props:  core.ResolverProps{"url": "http://localhost:12345/payments/$id"},
+	}}
+
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+
+	for i := range errs {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if _, err := gj.GraphQL(context.Background(), gql, nil, nil); err != nil {
+					errs[n] = err
+					return
+				}
+			}
+		}(i)
+	}
+
+	for i := 0; i < 5; i++ {
+		if err := gj.Reload(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
 }
 
 func queryWithVariableLimit(t *testing.T) {
@@ -43,6 +99,38 @@ func queryWithVariableLimit(t *testing.T) {
 	}
 }
 
+func queryWithTableFunction(t *testing.T) {
+	gql := `query {
+		products_in_price_range(min_price: $min, max_price: 15, order_by: { price: desc }, limit: 2) {
+			id
+			owner {
+				id
+			}
+		}
+	}`
+
+	vars := json.RawMessage(`{
+		"min": 12
+	}`)
+
+	conf := &core.Config{DBType: dbType, DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		t.Error(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, vars, nil)
+
+	switch dbType {
+	case "mysql":
+		assert.NotNil(t, err)
+	default:
+		exp := `{"products_in_price_range": [{"id": 4, "owner": {"id": 4}}, {"id": 3, "owner": {"id": 3}}]}`
+		got := string(res.Data)
+		assert.Equal(t, got, exp, "should equal")
+	}
+}
+
 var benchGQL = `query {
 	products(
 		# returns only 30 items
@@ -0,0 +1,45 @@
+package core
+
+// engine returns the current engine
+func (g *GraphJin) engine() *graphjin {
+	return g.e.Load().(*graphjin)
+}
+
+// Reload function rebuilds GraphJin from the current database schema, use it
+// to pick up tables, columns and relationships added after GraphJin was created.
+// The database is queried again to learn its schema (or the schema snapshot is
+// read again when one is configured), the compilers and the
+// introspection schema are rebuilt and then swapped in at once. Queries from the
+// allow list are compiled again against the new schema. Requests and subscriptions
+// already in progress continue with the previous schema.
+func (g *GraphJin) Reload() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	gj := g.engine()
+
+	ngj, err := newEngine(gj.conf.copy(), gj.db, nil, gj)
+	if err != nil {
+		return err
+	}
+
+	g.e.Store(ngj)
+	return nil
+}
+
+// copy returns a copy of the config for a new engine, the slices and maps
+// changed while building an engine are copied so the config of the current
+// engine is not changed while it's serving requests
+func (c *Config) copy() *Config {
+	nc := *c
+
+	nc.Roles = append([]Role(nil), c.Roles...)
+	nc.RootFields = append([]RootField(nil), c.RootFields...)
+
+	nc.rtmap = make(map[string]resFn, len(c.rtmap))
+	for k, v := range c.rtmap {
+		nc.rtmap[k] = v
+	}
+
+	return &nc
+}
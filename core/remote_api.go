@@ -1,16 +1,34 @@
 package core
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httputil"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/dosco/graphjin/internal/jsn"
+	cache "github.com/go-pkgz/expirable-cache"
 	"github.com/mitchellh/mapstructure"
 )
 
+const (
+	remoteTimeout        = 10 * time.Second
+	remoteRetryBackoff   = 100 * time.Millisecond
+	remoteBreakerTimeout = 30 * time.Second
+	remoteCacheSize      = 1000
+)
+
+var errCircuitOpen = errors.New("circuit breaker open: too many failed requests")
+
 // RemoteAPI struct defines a remote API endpoint
 type remoteAPI struct {
 	URL   string
@@ -21,53 +39,325 @@ type remoteAPI struct {
 		Name  string
 		Value string
 	} `mapstructure:"set_headers"`
+
+	// Timeout is the max time to wait for a response (defaults to 10s)
+	Timeout time.Duration
+
+	// Retries is the number of times a request is retried when it fails
+	// to connect or the server responds with a 429 or 5xx. The wait between
+	// retries starts at RetryBackoff (defaults to 100ms) and doubles each time.
+	Retries      int
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"`
+
+	// BreakerThreshold is the number of consecutive failed requests after
+	// which no requests are made for BreakerTimeout (defaults to 30s)
+	BreakerThreshold int           `mapstructure:"breaker_threshold"`
+	BreakerTimeout   time.Duration `mapstructure:"breaker_timeout"`
+
+	// CacheTTL enables caching of responses keyed by the URL, the body
+	// of batch POST requests and the values of the passed headers.
+	CacheTTL  time.Duration `mapstructure:"cache_ttl"`
+	CacheSize int           `mapstructure:"cache_size"`
+
+	// Batch enables fetching the data for all the ids in a single
+	// request. With the GET method the comma separated list of ids
+	// replaces $ids in the URL and with the POST method a JSON array
+	// of ids is sent as the body. The response must be a JSON array.
+	Batch       bool
+	BatchMethod string `mapstructure:"batch_method"`
+
+	// BatchIDField is the field in each item of the batch response that
+	// contains the id. If not set the items are expected to be in the same
+	// order as the ids.
+	BatchIDField string `mapstructure:"batch_id_field"`
+
+	client *http.Client
+	cache  cache.Cache
+	cb     circuitBreaker
+}
+
+// remoteBatchAPI is a remote API endpoint with batching enabled
+type remoteBatchAPI struct {
+	*remoteAPI
+}
+
+func newRemoteAPI(v map[string]interface{}) (Resolver, error) {
+	ra, err := decodeRemoteAPI(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ra.Batch {
+		return ra, nil
+	}
+
+	switch strings.ToUpper(ra.BatchMethod) {
+	case "", "GET", "POST":
+	default:
+		return nil, fmt.Errorf("remote_api: invalid batch_method: %s", ra.BatchMethod)
+	}
+
+	return &remoteBatchAPI{ra}, nil
 }
 
-func newRemoteAPI(v map[string]interface{}) (*remoteAPI, error) {
+// decodeRemoteAPI decodes the remote API config from the resolver
+// props and sets up the http client and cache
+func decodeRemoteAPI(v map[string]interface{}) (*remoteAPI, error) {
 	ra := &remoteAPI{}
-	if err := mapstructure.Decode(v, ra); err != nil {
+
+	d, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     ra,
+	})
+	if err != nil {
 		return nil, err
 	}
+
+	if err := d.Decode(v); err != nil {
+		return nil, err
+	}
+
+	if ra.Timeout == 0 {
+		ra.Timeout = remoteTimeout
+	}
+
+	if ra.RetryBackoff == 0 {
+		ra.RetryBackoff = remoteRetryBackoff
+	}
+
+	if ra.BreakerTimeout == 0 {
+		ra.BreakerTimeout = remoteBreakerTimeout
+	}
+	ra.cb.threshold = ra.BreakerThreshold
+	ra.cb.timeout = ra.BreakerTimeout
+
+	ra.client = &http.Client{Timeout: ra.Timeout}
+
+	if ra.CacheTTL != 0 {
+		if ra.CacheSize == 0 {
+			ra.CacheSize = remoteCacheSize
+		}
+		ra.cache, err = cache.NewCache(cache.MaxKeys(ra.CacheSize), cache.TTL(ra.CacheTTL))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return ra, nil
 }
 
 func (r *remoteAPI) Resolve(rr ResolverReq) ([]byte, error) {
 	uri := strings.ReplaceAll(r.URL, "$id", rr.ID)
+	return r.request(rr, "GET", uri, nil)
+}
+
+func (r *remoteBatchAPI) ResolveBatch(rr ResolverReq) ([][]byte, error) {
+	var body []byte
+	var err error
 
-	req, err := http.NewRequest("GET", uri, nil)
+	uri := r.URL
+	method := strings.ToUpper(r.BatchMethod)
+
+	if method == "POST" {
+		if body, err = json.Marshal(rr.IDs); err != nil {
+			return nil, err
+		}
+	} else {
+		method = "GET"
+		ids := make([]string, len(rr.IDs))
+
+		for i := range rr.IDs {
+			ids[i] = url.QueryEscape(rr.IDs[i])
+		}
+		uri = strings.ReplaceAll(uri, "$ids", strings.Join(ids, ","))
+	}
+
+	b, err := r.request(rr, method, uri, body)
 	if err != nil {
 		return nil, err
 	}
 
-	// if host, ok := hdr["Host"]; ok {
-	// 	req.Host = host[0]
-	// }
+	var items []json.RawMessage
 
-	for _, v := range r.SetHeaders {
-		req.Header.Set(v.Name, v.Value)
+	if err := json.Unmarshal(b, &items); err != nil {
+		return nil, fmt.Errorf("batch response is not an array: %w", err)
 	}
 
-	// for _, v := range r.PassHeaders {
-	// 	req.Header.Set(v, hdr.Get(v))
-	// }
+	if r.BatchIDField == "" {
+		if len(items) != len(rr.IDs) {
+			return nil, fmt.Errorf("batch response has %d items expected %d",
+				len(items), len(rr.IDs))
+		}
+		res := make([][]byte, len(items))
+		for i := range items {
+			res[i] = items[i]
+		}
+		return res, nil
+	}
+
+	im := make(map[string][]byte, len(items))
+
+	for _, v := range items {
+		var item map[string]json.RawMessage
+
+		if err := json.Unmarshal(v, &item); err != nil {
+			return nil, err
+		}
+		if id, ok := item[r.BatchIDField]; ok {
+			im[string(jsn.Value(id))] = v
+		}
+	}
+
+	res := make([][]byte, len(rr.IDs))
+
+	for i, id := range rr.IDs {
+		if v, ok := im[id]; ok {
+			res[i] = v
+		} else {
+			res[i] = []byte("null")
+		}
+	}
+
+	return res, nil
+}
 
-	client := &http.Client{}
+// request makes the request to the remote API using the cache, circuit
+// breaker and retries configured for it
+func (r *remoteAPI) request(rr ResolverReq, method, uri string, body []byte) ([]byte, error) {
+	var key string
 
-	res, err := client.Do(req)
+	if r.cache != nil {
+		key = r.cacheKey(rr, method, uri, body)
+
+		if v, ok := r.cache.Get(key); ok {
+			return v.([]byte), nil
+		}
+	}
+
+	ctx := rr.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if !r.cb.allow() {
+		return nil, errCircuitOpen
+	}
+
+	var b []byte
+	var retry bool
+	var err error
+
+	backoff := r.RetryBackoff
+
+	for i := 0; ; i++ {
+		b, retry, err = r.send(ctx, rr, method, uri, body)
+		if !retry || i >= r.Retries {
+			break
+		}
+
+		t := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+		backoff *= 2
+	}
+
+	if err != nil {
+		// requests cancelled by the client are not
+		// counted as failures of the remote api
+		if ctx.Err() == nil {
+			r.cb.failure()
+		}
+		return nil, err
+	}
+	r.cb.success()
+
+	if r.cache != nil {
+		r.cache.Set(key, b, 0)
+	}
+
+	return b, nil
+}
+
+// cacheKey returns the key of the response in the cache, the values of
+// the passed headers are part of it since the response can depend on them
+// eg. a user's response fetched with their Authorization header
+func (r *remoteAPI) cacheKey(rr ResolverReq, method, uri string, body []byte) string {
+	var sb strings.Builder
+
+	sb.WriteString(method)
+	sb.WriteString(" ")
+	sb.WriteString(uri)
+	sb.WriteString(" ")
+	sb.Write(body)
+
+	if rr.ReqConfig == nil {
+		return sb.String()
+	}
+
+	for _, v := range r.PassHeaders {
+		sb.WriteString("\n")
+		sb.WriteString(v)
+		sb.WriteString(": ")
+		sb.WriteString(rr.Header.Get(v))
+	}
+	return sb.String()
+}
+
+// send makes a single request to the remote API, retry is true if
+// the request failed and can be retried
+func (r *remoteAPI) send(ctx context.Context, rr ResolverReq, method, uri string, body []byte) (
+	b []byte, retry bool, err error) {
+
+	var br io.Reader
+
+	if body != nil {
+		br = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, uri, br)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to '%s': %v", uri, err)
+		return nil, false, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if rr.ReqConfig != nil {
+		for _, v := range r.PassHeaders {
+			if hv := rr.Header.Get(v); hv != "" {
+				req.Header.Set(v, hv)
+			}
+		}
+	}
+
+	for _, v := range r.SetHeaders {
+		if strings.EqualFold(v.Name, "Host") {
+			req.Host = v.Value
+		} else {
+			req.Header.Set(v.Name, v.Value)
+		}
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to connect to '%s': %v", uri, err)
 	}
 	defer res.Body.Close()
 
 	if r.Debug {
 		reqDump, err := httputil.DumpRequestOut(req, true)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 
 		resDump, err := httputil.DumpResponse(res, true)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 
 		rr.Log.Printf("DBG Remote Request:\n%s\n%s",
@@ -75,18 +365,62 @@ func (r *remoteAPI) Resolve(rr ResolverReq) ([]byte, error) {
 	}
 
 	if res.StatusCode != 200 {
-		return nil,
+		retry = res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+		return nil, retry,
 			fmt.Errorf("server responded with a %d", res.StatusCode)
 	}
 
-	b, err := ioutil.ReadAll(res.Body)
+	b, err = ioutil.ReadAll(res.Body)
 	if err != nil {
-		return nil, err
+		return nil, true, err
 	}
 
 	if err := jsn.ValidateBytes(b); err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	return b, nil
+	return b, false, nil
+}
+
+// circuitBreaker stops requests to a remote API for a while after
+// too many consecutive requests have failed
+type circuitBreaker struct {
+	sync.Mutex
+	threshold int
+	timeout   time.Duration
+	failures  int
+	openUntil time.Time
+}
+
+func (cb *circuitBreaker) allow() bool {
+	if cb.threshold == 0 {
+		return true
+	}
+	cb.Lock()
+	defer cb.Unlock()
+
+	// once the timeout has passed requests are allowed again, if
+	// one of them fails the breaker opens again
+	return time.Now().After(cb.openUntil)
+}
+
+func (cb *circuitBreaker) success() {
+	if cb.threshold == 0 {
+		return
+	}
+	cb.Lock()
+	cb.failures = 0
+	cb.Unlock()
+}
+
+func (cb *circuitBreaker) failure() {
+	if cb.threshold == 0 {
+		return
+	}
+	cb.Lock()
+	cb.failures++
+	if cb.failures >= cb.threshold {
+		cb.openUntil = time.Now().Add(cb.timeout)
+	}
+	cb.Unlock()
 }
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dosco/graphjin/core/internal/qcode"
+	"github.com/mitchellh/mapstructure"
+)
+
+// remoteGraphQL struct defines a remote GraphQL endpoint, the fields selected
+// on the remote selector are sent as a query on the configured field with the
+// id passed as a variable. The arguments of the fields are sent as is, queries
+// using variables in them fail with a validation error
+type remoteGraphQL struct {
+	*remoteAPI
+
+	// Field is the root field of the remote query
+	Field string
+
+	// IDArg is the argument of the root field the id is passed as
+	// (defaults to id) and IDType its GraphQL type (defaults to ID!)
+	IDArg  string `mapstructure:"id_arg"`
+	IDType string `mapstructure:"id_type"`
+}
+
+type gqlResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func newRemoteGraphQL(v map[string]interface{}) (Resolver, error) {
+	ra, err := decodeRemoteAPI(v)
+	if err != nil {
+		return nil, err
+	}
+
+	rg := &remoteGraphQL{remoteAPI: ra}
+
+	if err := mapstructure.Decode(v, rg); err != nil {
+		return nil, err
+	}
+
+	if rg.Field == "" {
+		return nil, errors.New("graphql: field is required")
+	}
+
+	if rg.IDArg == "" {
+		rg.IDArg = "id"
+	}
+
+	if rg.IDType == "" {
+		rg.IDType = "ID!"
+	}
+
+	return rg, nil
+}
+
+func (r *remoteGraphQL) Resolve(rr ResolverReq) ([]byte, error) {
+	// the id is sent as is so numeric ids
+	// work with types like Int!
+	id := rr.RawID
+	if len(id) == 0 {
+		v, err := json.Marshal(rr.ID)
+		if err != nil {
+			return nil, err
+		}
+		id = v
+	}
+
+	req := struct {
+		Query     string                     `json:"query"`
+		Variables map[string]json.RawMessage `json:"variables"`
+	}{
+		Query:     r.query(rr.Sel),
+		Variables: map[string]json.RawMessage{"id": id},
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := r.request(rr, "POST", r.URL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var res gqlResponse
+
+	if err := json.Unmarshal(b, &res); err != nil {
+		return nil, err
+	}
+
+	if len(res.Errors) != 0 {
+		msg := make([]string, len(res.Errors))
+		for i, e := range res.Errors {
+			msg[i] = e.Message
+		}
+		return nil, errors.New(strings.Join(msg, ", "))
+	}
+
+	if v, ok := res.Data[r.Field]; ok {
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("field '%s' missing from response", r.Field)
+}
+
+// query renders the GraphQL query for the fields selected
+// on the remote selector
+func (r *remoteGraphQL) query(sel *qcode.Select) string {
+	var sb strings.Builder
+
+	sb.WriteString(`query ($id: `)
+	sb.WriteString(r.IDType)
+	sb.WriteString(`) { `)
+	sb.WriteString(r.Field)
+	sb.WriteString(`(`)
+	sb.WriteString(r.IDArg)
+	sb.WriteString(`: $id)`)
+	renderFields(&sb, sel.Fields)
+	sb.WriteString(` }`)
+
+	return sb.String()
+}
+
+func renderFields(sb *strings.Builder, fields []qcode.Field) {
+	if len(fields) == 0 {
+		return
+	}
+
+	sb.WriteString(` {`)
+	for _, f := range fields {
+		sb.WriteByte(' ')
+		if f.Alias != "" {
+			sb.WriteString(f.Alias)
+			sb.WriteString(`: `)
+		}
+		sb.WriteString(f.Name)
+
+		if f.Args != "" {
+			sb.WriteByte('(')
+			sb.WriteString(f.Args)
+			sb.WriteByte(')')
+		}
+		renderFields(sb, f.Children)
+	}
+	sb.WriteString(` }`)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/dosco/graphjin/core/internal/qcode"
 	"github.com/dosco/graphjin/internal/jsn"
@@ -48,6 +49,20 @@ func (c *scontext) execRemoteJoin(res qres) (qres, error) {
 	return res, nil
 }
 
+// remoteBatch holds the ids of all the insertion points of a remote
+// selector that uses a batch resolver
+type remoteBatch struct {
+	r   resItem
+	br  BatchResolver
+	s   *qcode.Select
+	ids []string
+	idx map[string]int
+
+	// index of each insertion point and of its id in ids
+	items []int
+	pos   []int
+}
+
 func (c *scontext) resolveRemotes(
 	from []jsn.Field,
 	sel []qcode.Select,
@@ -57,10 +72,21 @@ func (c *scontext) resolveRemotes(
 	// key and value will be replaced by whats below
 	to := make([]jsn.Field, len(from))
 
-	var wg sync.WaitGroup
-	wg.Add(len(from))
+	// timings of each remote resolver used for tracing
+	ts := make([]TraceSpan, len(from))
+
+	// errors returned by the resolvers, the fields they failed
+	// to resolve are set to null
+	rerr := make([]error, len(from))
 
-	var cerr error
+	// errors building the fields from the resolved values, these
+	// fail the whole request
+	cerr := make([]error, len(from))
+
+	// insertion points of batch resolvers grouped by selector
+	batches := make(map[int32]*remoteBatch)
+
+	var wg sync.WaitGroup
 
 	for i, id := range from {
 		// use the json key to find the related Select object
@@ -77,46 +103,160 @@ func (c *scontext) resolveRemotes(
 			return nil, fmt.Errorf("no resolver found")
 		}
 
-		id := jsn.Value(id.Value)
+		raw := id.Value
+		id := jsn.Value(raw)
 		if len(id) == 0 {
 			return nil, fmt.Errorf("invalid remote field id")
 		}
 
-		go func(n int, id []byte, s *qcode.Select) {
+		if br, ok := r.Fn.(BatchResolver); ok {
+			b, ok := batches[s.ID]
+			if !ok {
+				b = &remoteBatch{r: r, br: br, s: s, idx: make(map[string]int)}
+				batches[s.ID] = b
+			}
+
+			n, ok := b.idx[string(id)]
+			if !ok {
+				n = len(b.ids)
+				b.idx[string(id)] = n
+				b.ids = append(b.ids, string(id))
+			}
+
+			b.items = append(b.items, i)
+			b.pos = append(b.pos, n)
+			continue
+		}
+
+		wg.Add(1)
+		go func(n int, id, raw []byte, s *qcode.Select) {
 			defer wg.Done()
 
-			//st := time.Now()
+			st := time.Now()
+			span := c.startSpan("remote: " + s.Table)
 
 			b, err := r.Fn.Resolve(ResolverReq{
-				ID: string(id), Sel: s, Log: c.gj.log, ReqConfig: c.rc})
+				ID: string(id), RawID: raw, Sel: s, Log: c.gj.log, Context: c, ReqConfig: c.rc})
+
+			span.End()
+
+			if c.tr != nil {
+				ts[n] = c.tr.span(st)
+			}
+
 			if err != nil {
-				cerr = fmt.Errorf("%s: %s", s.Table, err)
+				rerr[n] = err
 				return
 			}
 
-			if len(r.Path) != 0 {
-				b = jsn.Strip(b, r.Path)
+			if to[n], err = remoteField(r, s, b); err != nil {
+				cerr[n] = err
 			}
+		}(i, id, raw, s)
+	}
 
-			var ob bytes.Buffer
+	for _, b := range batches {
+		wg.Add(1)
+		go func(b *remoteBatch) {
+			defer wg.Done()
 
-			if len(s.Cols) != 0 {
-				err = jsn.Filter(&ob, b, colsToList(s.Cols))
-				if err != nil {
-					cerr = fmt.Errorf("%s: %w", s.Table, err)
-					return
+			st := time.Now()
+			span := c.startSpan("remote: " + b.s.Table)
+
+			res, err := b.br.ResolveBatch(ResolverReq{
+				IDs: b.ids, Sel: b.s, Log: c.gj.log, Context: c, ReqConfig: c.rc})
+
+			span.End()
+
+			if c.tr != nil {
+				t := c.tr.span(st)
+				for _, n := range b.items {
+					ts[n] = t
 				}
+			}
 
-			} else {
-				ob.WriteString("null")
+			if err == nil && len(res) != len(b.ids) {
+				err = fmt.Errorf("batch resolver returned %d values expected %d",
+					len(res), len(b.ids))
+			}
+
+			// a single error is reported for the whole batch
+			if err != nil {
+				rerr[b.items[0]] = err
+				for _, n := range b.items[1:] {
+					to[n] = nullField(b.s)
+				}
+				return
 			}
 
-			to[n] = jsn.Field{Key: []byte(s.FieldName), Value: ob.Bytes()}
-		}(i, id, s)
+			for i, n := range b.items {
+				if to[n], err = remoteField(b.r, b.s, res[b.pos[i]]); err != nil {
+					cerr[n] = err
+					return
+				}
+			}
+		}(b)
 	}
 	wg.Wait()
 
-	return to, cerr
+	for _, err := range cerr {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i, err := range rerr {
+		if err == nil {
+			continue
+		}
+		s := sfmap[string(from[i].Key)]
+		to[i] = nullField(s)
+
+		e := newError(wrapErr(ErrRemote, fmt.Errorf("%s: %s", s.Table, err)))
+		e.Path = selectorPath(sel, s.ID)
+		c.errs = append(c.errs, e)
+	}
+
+	if c.tr != nil {
+		// index of the parent row of each insertion point
+		rows := make(map[int32]int)
+
+		for i, id := range from {
+			if s, ok := sfmap[string(id.Key)]; ok {
+				c.tr.addResolver(c.op, sel, s.ID, rows[s.ID], ts[i])
+				rows[s.ID]++
+			}
+		}
+	}
+
+	return to, nil
+}
+
+func nullField(s *qcode.Select) jsn.Field {
+	return jsn.Field{Key: []byte(s.FieldName), Value: []byte("null")}
+}
+
+// remoteField returns the replacement for an insertion point
+// using the columns selected from the remote data
+func remoteField(r resItem, s *qcode.Select, b []byte) (jsn.Field, error) {
+	if len(r.Path) != 0 {
+		b = jsn.Strip(b, r.Path)
+	}
+
+	var ob bytes.Buffer
+
+	// no remote data was found for this id
+	isNull := bytes.Equal(bytes.TrimSpace(b), []byte("null"))
+
+	if len(s.Cols) != 0 && !isNull {
+		if err := jsn.Filter(&ob, b, colsToList(s.Cols)); err != nil {
+			return jsn.Field{}, fmt.Errorf("%s: %w", s.Table, err)
+		}
+	} else {
+		ob.WriteString("null")
+	}
+
+	return jsn.Field{Key: []byte(s.FieldName), Value: ob.Bytes()}, nil
 }
 
 func (c *scontext) parentFieldIds(sel []qcode.Select, remotes int32) (
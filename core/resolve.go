@@ -15,15 +15,27 @@ type resItem struct {
 	Fn      Resolver
 }
 
-func (gj *GraphJin) initResolvers() error {
+func (gj *graphjin) initResolvers() error {
 	gj.rmap = make(map[string]resItem)
 
-	err := gj.conf.SetResolver("remote_api", func(v ResolverProps) (Resolver, error) {
-		return newRemoteAPI(v)
-	})
+	rtmap := map[string]resFn{
+		"remote_api": func(v ResolverProps) (Resolver, error) {
+			return newRemoteAPI(v)
+		},
+		"graphql": func(v ResolverProps) (Resolver, error) {
+			return newRemoteGraphQL(v)
+		},
+	}
 
-	if err != nil {
-		return err
+	for name, fn := range rtmap {
+		// the config is reused by Reload so the built-in
+		// resolvers are only set the first time
+		if _, ok := gj.conf.rtmap[name]; ok {
+			continue
+		}
+		if err := gj.conf.SetResolver(name, fn); err != nil {
+			return err
+		}
 	}
 
 	for _, r := range gj.conf.Resolvers {
@@ -35,7 +47,7 @@ func (gj *GraphJin) initResolvers() error {
 	return nil
 }
 
-func (gj *GraphJin) initRemote(rc ResolverConfig) error {
+func (gj *graphjin) initRemote(rc ResolverConfig) error {
 	// Defines the table column to be used as an id in the
 	// remote reques
 	var col sdata.DBColumn
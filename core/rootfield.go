@@ -0,0 +1,329 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/chirino/graphql/schema"
+	"github.com/dosco/graphjin/core/internal/qcode"
+	"github.com/dosco/graphjin/internal/jsn"
+)
+
+// RootFieldFunc is the function called to resolve a root field, args is a JSON object
+// with the values of the arguments. The returned JSON is used as the value of the field.
+type RootFieldFunc func(c context.Context, args json.RawMessage) ([]byte, error)
+
+// RootField struct defines a top-level query or mutation field that's resolved by a Go
+// function instead of the database. For example to fetch the current weather or to send
+// an email. The output of the function is merged into the result along with the data
+// from the database.
+//
+// The functions of mutation fields are called after the database mutations of the
+// request are committed, a failing function does not roll them back. With GraphQLTx
+// they are called before the caller commits the transaction.
+type RootField struct {
+	Name string
+	Desc string
+
+	// Mutation adds the field to the mutations instead of the queries
+	Mutation bool
+
+	// Roles allowed to use the field, defaults to all roles
+	Roles []string
+
+	// Type is the GraphQL type of the value returned by the function
+	// eg. String!, [Int] or JSON (defaults to JSON)
+	Type string
+
+	Args []RootFieldArg
+	Fn   RootFieldFunc
+}
+
+// RootFieldArg struct defines an argument of a root field, the value
+// is validated against the GraphQL type eg. String!, [Int] or JSON
+type RootFieldArg struct {
+	Name string
+	Desc string
+	Type string
+}
+
+type rootField struct {
+	*RootField
+	typ  schema.Type
+	args map[string]schema.Type
+}
+
+func (gj *graphjin) initRootFields() error {
+	gj.rfmap = make(map[string]rootField, len(gj.conf.RootFields))
+
+	for i := range gj.conf.RootFields {
+		f := &gj.conf.RootFields[i]
+
+		if f.Name == "" || f.Fn == nil {
+			return errors.New("root fields: name and function required")
+		}
+
+		if _, ok := gj.rfmap[f.Name]; ok {
+			return fmt.Errorf("root fields: duplicate field: %s", f.Name)
+		}
+
+		if f.Type == "" {
+			f.Type = "JSON"
+		}
+
+		rf := rootField{RootField: f, args: make(map[string]schema.Type, len(f.Args))}
+
+		var err error
+
+		if rf.typ, err = parseGQLType(f.Type); err != nil {
+			return fmt.Errorf("root fields: %s: %w", f.Name, err)
+		}
+
+		for _, a := range f.Args {
+			if rf.args[a.Name], err = parseGQLType(a.Type); err != nil {
+				return fmt.Errorf("root fields: %s: %s: %w", f.Name, a.Name, err)
+			}
+		}
+
+		gj.rfmap[f.Name] = rf
+	}
+
+	return nil
+}
+
+// checkRootFields validates the arguments of the root fields
+// resolved by functions against their types
+func (gj *graphjin) checkRootFields(qc *qcode.QCode, vars []byte) error {
+	if len(qc.RootFields) == 0 {
+		return nil
+	}
+
+	var vm qcode.Variables
+
+	if len(vars) != 0 {
+		if err := json.Unmarshal(vars, &vm); err != nil {
+			return fmt.Errorf("variables: %w", err)
+		}
+	}
+
+	for _, f := range qc.RootFields {
+		rf := gj.rfmap[f.Name]
+
+		d := json.NewDecoder(bytes.NewReader(f.Args(vm)))
+		d.UseNumber()
+
+		var args map[string]interface{}
+
+		if err := d.Decode(&args); err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+
+		for k := range args {
+			if _, ok := rf.args[k]; !ok {
+				return fmt.Errorf("%s: unknown argument '%s'", f.Name, k)
+			}
+		}
+
+		for k, t := range rf.args {
+			if err := checkArgValue(t, args[k]); err != nil {
+				return fmt.Errorf("%s: argument '%s': %w", f.Name, k, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// execRootFields calls the functions of the root fields and adds their
+// output to the data returned from the database. Fields whose function
+// fails are set to null with the error added to the result.
+func (c *scontext) execRootFields(res qres, vars []byte) (qres, error) {
+	qc := res.q.st.qc
+
+	if qc == nil || len(qc.RootFields) == 0 {
+		return res, nil
+	}
+
+	var vm qcode.Variables
+
+	if len(vars) != 0 {
+		if err := json.Unmarshal(vars, &vm); err != nil {
+			return res, wrapErr(ErrValidation, fmt.Errorf("variables: %w", err))
+		}
+	}
+
+	var b bytes.Buffer
+	d := bytes.TrimSpace(res.data)
+
+	if len(d) > 1 {
+		b.Write(d[:len(d)-1])
+	} else {
+		b.WriteByte('{')
+	}
+
+	for _, f := range qc.RootFields {
+		if b.Len() > 1 {
+			b.WriteString(", ")
+		}
+		writeJSONString(&b, f.FieldName)
+		b.WriteString(": ")
+
+		v, err := c.resolveRootField(f, vm)
+		if err != nil {
+			e := newError(fmt.Errorf("%s: %w", f.Name, err))
+			e.Path = []string{f.FieldName}
+			c.errs = append(c.errs, e)
+			v = []byte("null")
+		}
+		b.Write(v)
+	}
+
+	b.WriteByte('}')
+	res.data = b.Bytes()
+
+	return res, nil
+}
+
+func (c *scontext) resolveRootField(f qcode.RootField, vars qcode.Variables) ([]byte, error) {
+	rf := c.gj.rfmap[f.Name]
+
+	span := c.startSpan("root field: " + f.Name)
+	defer span.End()
+
+	v, err := rf.Fn(c, f.Args(vars))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bytes.TrimSpace(v)) == 0 {
+		return []byte("null"), nil
+	}
+
+	if err := jsn.ValidateBytes(v); err != nil {
+		return nil, err
+	}
+
+	if len(f.Fields) == 0 || bytes.Equal(bytes.TrimSpace(v), []byte("null")) {
+		return v, nil
+	}
+
+	keys := make([]string, len(f.Fields))
+	for i, cf := range f.Fields {
+		keys[i] = cf.Name
+	}
+
+	var ob bytes.Buffer
+
+	if err := jsn.Filter(&ob, v, keys); err != nil {
+		return nil, err
+	}
+	return ob.Bytes(), nil
+}
+
+// parseGQLType parses a GraphQL type like [String!]!
+func parseGQLType(s string) (schema.Type, error) {
+	s = strings.TrimSpace(s)
+
+	switch {
+	case s == "":
+		return nil, errors.New("type missing")
+
+	case strings.HasSuffix(s, "!"):
+		t, err := parseGQLType(s[:len(s)-1])
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := t.(*schema.NonNull); ok {
+			return nil, fmt.Errorf("invalid type: %s", s)
+		}
+		return &schema.NonNull{OfType: t}, nil
+
+	case s[0] == '[':
+		if !strings.HasSuffix(s, "]") {
+			return nil, fmt.Errorf("invalid type: %s", s)
+		}
+		t, err := parseGQLType(s[1 : len(s)-1])
+		if err != nil {
+			return nil, err
+		}
+		return &schema.List{OfType: t}, nil
+	}
+
+	for _, r := range s {
+		if !(r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			return nil, fmt.Errorf("invalid type: %s", s)
+		}
+	}
+
+	return &schema.TypeName{Name: s}, nil
+}
+
+// checkArgValue checks if the value decoded from JSON (using json.Number)
+// is valid for the GraphQL type
+func checkArgValue(t schema.Type, v interface{}) error {
+	if nn, ok := t.(*schema.NonNull); ok {
+		if v == nil {
+			return errors.New("value required")
+		}
+		t = nn.OfType
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	switch t := t.(type) {
+	case *schema.List:
+		// a single value is accepted as a list of one
+		l, ok := v.([]interface{})
+		if !ok {
+			l = []interface{}{v}
+		}
+		for _, v1 := range l {
+			if err := checkArgValue(t.OfType, v1); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *schema.TypeName:
+		var ok bool
+
+		switch t.Name {
+		case "Int":
+			var n json.Number
+			if n, ok = v.(json.Number); ok {
+				_, err := n.Int64()
+				ok = (err == nil)
+			}
+		case "Float":
+			_, ok = v.(json.Number)
+		case "String":
+			_, ok = v.(string)
+		case "Boolean":
+			_, ok = v.(bool)
+		case "ID":
+			switch v.(type) {
+			case string, json.Number:
+				ok = true
+			}
+		default:
+			ok = true
+		}
+
+		if !ok {
+			return fmt.Errorf("expecting a value of type %s", t.Name)
+		}
+	}
+
+	return nil
+}
+
+func writeJSONString(b *bytes.Buffer, s string) {
+	v, _ := json.Marshal(s)
+	b.Write(v)
+}
@@ -0,0 +1,36 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dosco/graphjin/core/internal/sdata"
+)
+
+// WriteSchemaSnapshot function writes the database schema discovered by GraphJin to a
+// JSON snapshot. Set the SchemaSnapshot config value to the path of the snapshot file to
+// start GraphJin without querying the database for its schema.
+func (g *GraphJin) WriteSchemaSnapshot(w io.Writer) error {
+	return g.engine().dbinfo.WriteSnapshot(w)
+}
+
+func readSchemaSnapshot(conf *Config) (*sdata.DBInfo, error) {
+	f, err := os.Open(conf.SchemaSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	di, err := sdata.ReadSnapshot(f, conf.Blocklist)
+	if err != nil {
+		return nil, err
+	}
+
+	if di.Type != conf.DBType {
+		return nil, fmt.Errorf("schema snapshot: database type is '%s' not '%s'",
+			di.Type, conf.DBType)
+	}
+
+	return di, nil
+}
@@ -66,19 +66,43 @@ type Member struct {
 }
 
 // GraphQLEx is the extended version of the Subscribe function allowing for request specific config.
-func (gj *GraphJin) Subscribe(
+func (g *GraphJin) Subscribe(
 	c context.Context,
 	query string,
 	vars json.RawMessage,
 	rc *ReqConfig) (*Member, error) {
+	return g.SubscribeEx(c, query, "", vars, rc)
+}
+
+// SubscribeEx is the extended version of the Subscribe function, it takes the name of the
+// operation to subscribe to when the query contains more than one operation.
+func (g *GraphJin) SubscribeEx(
+	c context.Context,
+	query string,
+	opName string,
+	vars json.RawMessage,
+	rc *ReqConfig) (*Member, error) {
+	return g.engine().subscribe(c, query, opName, vars, rc)
+}
+
+func (gj *graphjin) subscribe(
+	c context.Context,
+	query string,
+	opName string,
+	vars json.RawMessage,
+	rc *ReqConfig) (*Member, error) {
 	var err error
 
-	op, name := qcode.GetQType(query)
+	op, name := qcode.GetQType(query, opName)
 
 	if op != qcode.QTSubscription {
 		return nil, errors.New("subscription: not a subscription query")
 	}
 
+	if opName == "" {
+		opName = name
+	}
+
 	if name == "" {
 		if gj.allowList != nil && gj.conf.EnforceAllowList {
 			return nil, errors.New("subscription: query name is required")
@@ -106,7 +130,7 @@ func (gj *GraphJin) Subscribe(
 	s := v.(*sub)
 
 	s.Do(func() {
-		err = gj.newSub(c, s, query, vars)
+		err = gj.newSub(c, s, query, opName, vars)
 	})
 
 	if err != nil {
@@ -130,10 +154,10 @@ func (gj *GraphJin) Subscribe(
 	return m, nil
 }
 
-func (gj *GraphJin) newSub(c context.Context, s *sub, query string, vars json.RawMessage) error {
+func (gj *graphjin) newSub(c context.Context, s *sub, query, opName string, vars json.RawMessage) error {
 	rq := rquery{
 		op:    qcode.QTSubscription,
-		name:  s.name,
+		name:  opName,
 		query: []byte(query),
 		vars:  vars,
 	}
@@ -151,7 +175,7 @@ func (gj *GraphJin) newSub(c context.Context, s *sub, query string, vars json.Ra
 	return nil
 }
 
-func (gj *GraphJin) subController(s *sub) {
+func (gj *graphjin) subController(s *sub) {
 	defer gj.subs.Delete((s.name + s.role))
 	var ps time.Duration
 
@@ -249,7 +273,7 @@ func (s *sub) updateMember(msg mmsg) error {
 	return nil
 }
 
-func (s *sub) fanOutJobs(gj *GraphJin) {
+func (s *sub) fanOutJobs(gj *graphjin) {
 	switch {
 	case len(s.ids) == 0:
 		return
@@ -266,7 +290,7 @@ func (s *sub) fanOutJobs(gj *GraphJin) {
 	}
 }
 
-func (gj *GraphJin) checkUpdates(s *sub, mv mval, start int) {
+func (gj *graphjin) checkUpdates(s *sub, mv mval, start int) {
 	// Do not use the `mval` embedded inside sub since
 	// its not thread safe use the copy `mv mval`.
 
@@ -284,15 +308,28 @@ func (gj *GraphJin) checkUpdates(s *sub, mv mval, start int) {
 
 	hasParams := len(s.q.st.md.Params()) != 0
 	c := context.Background()
+	q := s.q.st.sql
+
+	// use the same query timeout as the role would
+	// with a normal query
+	if d := gj.queryTimeout(s.role); d != 0 {
+		var cancel context.CancelFunc
+		c, cancel = context.WithTimeout(c, d)
+		defer cancel()
+
+		if gj.schema.DBType() == "mysql" {
+			q = addTimeoutHint(q, d)
+		}
+	}
 
 	// when params are not available we use a more optimized
 	// codepath that does not use a join query
 	// more details on this optimization are towards the end
 	// of the function
 	if hasParams {
-		rows, err = gj.db.QueryContext(c, s.q.st.sql, renderJSONArray(mv.params[start:end]))
+		rows, err = gj.db.QueryContext(c, q, renderJSONArray(mv.params[start:end]))
 	} else {
-		rows, err = gj.db.QueryContext(c, s.q.st.sql)
+		rows, err = gj.db.QueryContext(c, q)
 	}
 
 	if err != nil {
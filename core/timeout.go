@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// queryTimeout returns the query timeout of the role or if not
+// set the global query timeout
+func (gj *graphjin) queryTimeout(role string) time.Duration {
+	if r, ok := gj.roles[role]; ok && r.QueryTimeout != 0 {
+		return r.QueryTimeout
+	}
+	return gj.conf.QueryTimeout
+}
+
+// setStatementTimeout sets the statement timeout on Postgres, since 'SET LOCAL'
+// only works within a transaction the connection must be in one.
+func (c *scontext) setStatementTimeout(conn dbConn, timeout time.Duration) error {
+	if timeout == 0 || c.gj.schema.DBType() == "mysql" {
+		return nil
+	}
+
+	_, err := conn.ExecContext(c, `SET LOCAL statement_timeout = `+timeoutMillis(timeout))
+	return err
+}
+
+// withTimeoutHint adds the max execution time optimizer hint to MySQL queries
+func (c *scontext) withTimeoutHint(sql string, timeout time.Duration) string {
+	if timeout == 0 || c.gj.schema.DBType() != "mysql" {
+		return sql
+	}
+	return addTimeoutHint(sql, timeout)
+}
+
+func addTimeoutHint(sql string, timeout time.Duration) string {
+	if !strings.HasPrefix(sql, "SELECT ") {
+		return sql
+	}
+	return "SELECT /*+ MAX_EXECUTION_TIME(" + timeoutMillis(timeout) + ") */ " + sql[7:]
+}
+
+// timeoutMillis returns the timeout in milliseconds rounded up since
+// a timeout of 0 disables the statement timeout
+func timeoutMillis(timeout time.Duration) string {
+	ms := (timeout + time.Millisecond - 1) / time.Millisecond
+	return strconv.FormatInt(int64(ms), 10)
+}
+
+func (c *scontext) commitTx(tx *sql.Tx) error {
+	if tx == nil {
+		return nil
+	}
+	return c.dbErr(tx.Commit())
+}
+
+// dbErr tags database errors caused by a query timeout
+// with ErrTimeout and the rest with ErrDB
+func (c *scontext) dbErr(err error) error {
+	if isTimeoutErr(c, err) {
+		return wrapErr(ErrTimeout, err)
+	}
+	return wrapErr(ErrDB, err)
+}
+
+func isTimeoutErr(c context.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || c.Err() == context.DeadlineExceeded {
+		return true
+	}
+
+	// postgres: query_canceled (statement timeout)
+	var se interface{ SQLState() string }
+	if errors.As(err, &se) && se.SQLState() == "57014" {
+		return true
+	}
+
+	// mysql: max_execution_time exceeded (error 3024)
+	return strings.Contains(err.Error(), "maximum statement execution time exceeded")
+}
@@ -0,0 +1,160 @@
+package core
+
+import (
+	"time"
+
+	"github.com/dosco/graphjin/core/internal/qcode"
+	"go.opencensus.io/trace"
+)
+
+// Extensions struct contains the extensions returned with the result, currently
+// only the Apollo tracing extension is supported
+type Extensions struct {
+	Tracing *Trace `json:"tracing,omitempty"`
+}
+
+// Trace struct contains the timings of a request in the Apollo tracing format
+// https://github.com/apollographql/apollo-tracing. The time taken to compile
+// the query is reported as parsing, processing the variables as validation and
+// the time taken by the database is reported as database.
+type Trace struct {
+	Version    int            `json:"version"`
+	StartTime  time.Time      `json:"startTime"`
+	EndTime    time.Time      `json:"endTime"`
+	Duration   time.Duration  `json:"duration"`
+	Parsing    TraceSpan      `json:"parsing"`
+	Validation TraceSpan      `json:"validation"`
+	Database   TraceSpan      `json:"database"`
+	Execution  TraceExecution `json:"execution"`
+}
+
+// TraceSpan struct contains the start offset (from the start of the request) and
+// the duration of a step
+type TraceSpan struct {
+	StartOffset time.Duration `json:"startOffset"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// TraceExecution struct contains the timings of each selector in the query
+type TraceExecution struct {
+	Resolvers []TraceResolver `json:"resolvers"`
+}
+
+// TraceResolver struct contains the timing of a single selector. Selectors fetched
+// from the database share the timing of the database query while remote selectors
+// are timed individually, once for each row of their parent. The path of these has
+// the index of the parent row (in the order of the response) before the field name
+type TraceResolver struct {
+	Path        []interface{} `json:"path"`
+	ParentType  string        `json:"parentType"`
+	FieldName   string        `json:"fieldName"`
+	ReturnType  string        `json:"returnType"`
+	StartOffset time.Duration `json:"startOffset"`
+	Duration    time.Duration `json:"duration"`
+}
+
+func newTrace() *Trace {
+	return &Trace{Version: 1, StartTime: time.Now()}
+}
+
+func (t *Trace) end() {
+	t.EndTime = time.Now()
+	t.Duration = t.EndTime.Sub(t.StartTime)
+}
+
+// span returns the timing of a step that started at st and ended now
+func (t *Trace) span(st time.Time) TraceSpan {
+	return TraceSpan{
+		StartOffset: st.Sub(t.StartTime),
+		Duration:    time.Since(st),
+	}
+}
+
+// addDatabase adds the database timing and an entry for each selector
+// fetched from the database
+func (t *Trace) addDatabase(qc *qcode.QCode, ts TraceSpan) {
+	if t.Database.Duration == 0 {
+		t.Database.StartOffset = ts.StartOffset
+	}
+	t.Database.Duration += ts.Duration
+
+	for i := range qc.Selects {
+		if qc.Selects[i].SkipRender != qcode.SkipTypeNone {
+			continue
+		}
+		t.addResolver(qc.Type, qc.Selects, int32(i), -1, ts)
+	}
+}
+
+// addResolver adds an entry for the selector, if index is not -1
+// it's added to the path before the name of the selector
+func (t *Trace) addResolver(qt qcode.QType, sel []qcode.Select, id int32, index int, ts TraceSpan) {
+	s := &sel[id]
+	sp := selectorPath(sel, id)
+
+	path := make([]interface{}, 0, len(sp)+1)
+	for i, v := range sp {
+		if i == len(sp)-1 && index != -1 {
+			path = append(path, index)
+		}
+		path = append(path, v)
+	}
+
+	var parentType string
+
+	if s.ParentID == -1 {
+		switch qt {
+		case qcode.QTMutation:
+			parentType = "Mutation"
+		case qcode.QTSubscription:
+			parentType = "Subscription"
+		default:
+			parentType = "Query"
+		}
+	} else {
+		parentType = sel[s.ParentID].Table
+	}
+
+	returnType := s.Table
+	if !s.Singular {
+		returnType = "[" + returnType + "]"
+	}
+
+	t.Execution.Resolvers = append(t.Execution.Resolvers, TraceResolver{
+		Path:        path,
+		ParentType:  parentType,
+		FieldName:   s.FieldName,
+		ReturnType:  returnType,
+		StartOffset: ts.StartOffset,
+		Duration:    ts.Duration,
+	})
+}
+
+// selectorPath returns the field names from the root selector
+// down to the selector
+func selectorPath(sel []qcode.Select, id int32) []string {
+	n := 0
+	for i := id; i != -1; i = sel[i].ParentID {
+		n++
+	}
+
+	path := make([]string, n)
+	for i := id; i != -1; i = sel[i].ParentID {
+		n--
+		path[n] = sel[i].FieldName
+	}
+	return path
+}
+
+// tracingEnabled returns true when tracing is enabled in the config or
+// for the current request
+func (gj *graphjin) tracingEnabled(rc *ReqConfig) bool {
+	return gj.conf.EnableTracing || (rc != nil && rc.EnableTracing)
+}
+
+// startSpan starts an OpenCensus span as a child of the span in the context
+// if any. Spans are only exported if the request is sampled.
+func (c *scontext) startSpan(name string) *trace.Span {
+	_, span := trace.StartSpan(c, name)
+	return span
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"testing"
 
 	"github.com/dosco/graphjin/core"
+	"github.com/stretchr/testify/assert"
 )
 
 func Example_update() {
@@ -155,3 +157,45 @@ func Example_updateTableAndRelatedTable() {
 	}
 	// Output: {"users": {"products": [{"id": 90}], "full_name": "Updated user 90"}}
 }
+
+func TestMutationCallFunction(t *testing.T) {
+	// the price is set to the current price to not affect other tests
+	gql := `mutation {
+		set_product_price(product_id: $id, new_price: 110.5) {
+			id
+			price
+		}
+	}`
+
+	// procedures return nothing on mysql
+	if dbType == "mysql" {
+		gql = `mutation {
+			set_product_price(product_id: $id, new_price: 110.5)
+		}`
+	}
+
+	vars := json.RawMessage(`{ "id": 100 }`)
+
+	conf := &core.Config{
+		DBType:           dbType,
+		DisableAllowList: true,
+		Functions:        []core.Function{{Name: "set_product_price"}},
+	}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), core.UserIDKey, 3)
+	res, err := gj.GraphQL(ctx, gql, vars, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	switch dbType {
+	case "mysql":
+		assert.Equal(t, `{"set_product_price": null}`, string(res.Data))
+	default:
+		assert.Equal(t, `{"set_product_price": {"id": 100, "price": 110.5}}`, string(res.Data))
+	}
+}
@@ -41,8 +41,8 @@ type Serv struct {
 	Port           string
 	HTTPGZip       bool     `mapstructure:"http_compress"`
 	WebUI          bool     `mapstructure:"web_ui"`
-	EnableTracing  bool     `mapstructure:"enable_tracing"`
 	WatchAndReload bool     `mapstructure:"reload_on_config_change"`
+	WatchSchema    bool     `mapstructure:"reload_on_schema_change"`
 	AuthFailBlock  bool     `mapstructure:"auth_fail_block"`
 	SeedFile       string   `mapstructure:"seed_file"`
 	MigrationsPath string   `mapstructure:"migrations_path"`
@@ -50,6 +50,15 @@ type Serv struct {
 	DebugCORS      bool     `mapstructure:"cors_debug"`
 	APIPath        string   `mapstructure:"api_path"`
 	CacheControl   string   `mapstructure:"cache_control"`
+	MaxBatchSize   int      `mapstructure:"max_batch_size"`
+
+	// APQ struct contains automatic persisted queries related config
+	APQ struct {
+		Disable       bool
+		CacheSize     int    `mapstructure:"cache_size"`
+		RedisURL      string `mapstructure:"redis_url"`
+		RedisPassword string `mapstructure:"redis_password"`
+	} `mapstructure:"apq"`
 
 	// Telemetry struct contains OpenCensus metrics and tracing related config
 	Telemetry struct {
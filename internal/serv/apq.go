@@ -0,0 +1,174 @@
+package serv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+	cache "github.com/go-pkgz/expirable-cache"
+)
+
+const (
+	apqCacheSize = 1000
+	apqKeyPrefix = "apq:"
+
+	errCodePersistedQueryNotFound = "PERSISTED_QUERY_NOT_FOUND"
+)
+
+var (
+	errPersistedQueryNotFound = errors.New("PersistedQueryNotFound")
+	errPersistedQueryMismatch = errors.New("provided sha does not match query")
+	errGetMutation            = errors.New("mutations are not allowed over GET requests")
+)
+
+type extensions struct {
+	PersistedQuery *persistedQuery `json:"persistedQuery,omitempty"`
+}
+
+type persistedQuery struct {
+	Version    int    `json:"version"`
+	Sha256Hash string `json:"sha256Hash"`
+}
+
+// apqStore holds queries registered by clients using automatic
+// persisted queries, keyed by the sha256 hash of the query
+type apqStore interface {
+	Get(hash string) (string, bool, error)
+	Set(hash, query string) error
+}
+
+func newAPQStore(sc *ServConfig) apqStore {
+	conf := sc.conf.APQ
+
+	if conf.Disable || sc.conf.Production {
+		return nil
+	}
+
+	if conf.RedisURL != "" {
+		return newRedisAPQStore(conf.RedisURL, conf.RedisPassword)
+	}
+
+	size := conf.CacheSize
+	if size == 0 {
+		size = apqCacheSize
+	}
+
+	c, err := cache.NewCache(cache.MaxKeys(size), cache.LRU())
+	if err != nil {
+		sc.log.Fatalf("Error initializing persisted query cache: %s", err)
+	}
+
+	return &memAPQStore{c}
+}
+
+type memAPQStore struct {
+	c cache.Cache
+}
+
+func (s *memAPQStore) Get(hash string) (string, bool, error) {
+	v, ok := s.c.Get(hash)
+	if !ok {
+		return "", false, nil
+	}
+	return v.(string), true, nil
+}
+
+func (s *memAPQStore) Set(hash, query string) error {
+	s.c.Set(hash, query, 0)
+	return nil
+}
+
+type redisAPQStore struct {
+	rp *redis.Pool
+}
+
+func newRedisAPQStore(url, pwd string) *redisAPQStore {
+	rp := &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			c, err := redis.DialURL(url)
+			if err != nil {
+				return nil, err
+			}
+
+			if pwd != "" {
+				if _, err := c.Do("AUTH", pwd); err != nil {
+					return nil, err
+				}
+			}
+
+			return c, nil
+		},
+	}
+
+	return &redisAPQStore{rp}
+}
+
+func (s *redisAPQStore) Get(hash string) (string, bool, error) {
+	c := s.rp.Get()
+	defer c.Close()
+
+	v, err := redis.String(c.Do("GET", apqKeyPrefix+hash))
+	if err == redis.ErrNil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return v, true, nil
+}
+
+func (s *redisAPQStore) Set(hash, query string) error {
+	c := s.rp.Get()
+	defer c.Close()
+
+	_, err := c.Do("SET", apqKeyPrefix+hash, query)
+	return err
+}
+
+// resolvePersistedQuery fills in the query of a request that only
+// carries the hash of a persisted query. In production only queries
+// from the allow list can be looked up by hash
+func (sc *ServConfig) resolvePersistedQuery(req *gqlReq) error {
+	pq := req.Ext.PersistedQuery
+	if pq == nil || pq.Sha256Hash == "" {
+		return nil
+	}
+
+	hash := strings.ToLower(pq.Sha256Hash)
+
+	if req.Query != "" {
+		h := sha256.Sum256([]byte(req.Query))
+
+		if hex.EncodeToString(h[:]) != hash {
+			return errPersistedQueryMismatch
+		}
+
+		if sc.apq != nil {
+			if err := sc.apq.Set(hash, req.Query); err != nil {
+				return fmt.Errorf("persisted query: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if sc.apq != nil {
+		query, ok, err := sc.apq.Get(hash)
+		if err != nil {
+			return fmt.Errorf("persisted query: %w", err)
+		}
+		if ok {
+			req.Query = query
+			return nil
+		}
+	}
+
+	if query, ok := gj.PersistedQuery(hash); ok {
+		req.Query = query
+		return nil
+	}
+
+	return errPersistedQueryNotFound
+}
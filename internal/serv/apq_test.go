@@ -0,0 +1,93 @@
+package serv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dosco/graphjin/core"
+	cache "github.com/go-pkgz/expirable-cache"
+)
+
+func TestResolvePersistedQuery(t *testing.T) {
+	c, err := cache.NewCache(cache.MaxKeys(10), cache.LRU())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &ServConfig{apq: &memAPQStore{c}}
+
+	query := `query { products { id } }`
+	h := sha256.Sum256([]byte(query))
+	hash := hex.EncodeToString(h[:])
+
+	req := gqlReq{Query: query, Ext: extensions{&persistedQuery{1, "abc"}}}
+	if err := sc.resolvePersistedQuery(&req); err != errPersistedQueryMismatch {
+		t.Fatalf("expected hash mismatch error got: %v", err)
+	}
+
+	req = gqlReq{Query: query, Ext: extensions{&persistedQuery{1, hash}}}
+	if err := sc.resolvePersistedQuery(&req); err != nil {
+		t.Fatal(err)
+	}
+
+	req = gqlReq{Ext: extensions{&persistedQuery{1, hash}}}
+	if err := sc.resolvePersistedQuery(&req); err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Query != query {
+		t.Fatalf("expected query '%s' got '%s'", query, req.Query)
+	}
+}
+
+func TestBatchPersistedQueryErrors(t *testing.T) {
+	sc := &ServConfig{conf: &Config{}}
+
+	b := []byte(`[
+		{ "query": "query { products { id } }", "extensions": { "persistedQuery": { "version": 1, "sha256Hash": "abc" } } },
+		{ "query": "query { users { id } }", "extensions": { "persistedQuery": { "version": 1, "sha256Hash": "def" } } }
+	]`)
+
+	r := httptest.NewRequest("POST", "/api/v1/graphql", nil)
+	w := httptest.NewRecorder()
+
+	sc.apiV1Batch(w, r, b, nil)
+
+	var res []core.Result
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 2 {
+		t.Fatalf("expected a result for each request in the batch got: %s", w.Body.String())
+	}
+
+	for _, v := range res {
+		if len(v.Errors) != 1 || v.Errors[0].Extensions.Code != core.ErrCodeValidation {
+			t.Fatalf("expected a validation error for each request got: %s", w.Body.String())
+		}
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code string
+	}{
+		{errPersistedQueryNotFound, errCodePersistedQueryNotFound},
+		{errGetMutation, core.ErrCodeValidation},
+		{fmt.Errorf("users: %w", core.ErrNotFound), core.ErrCodeNotFound},
+		{fmt.Errorf("payments: %w", core.ErrRemote), core.ErrCodeRemote},
+		{errors.New("unknown"), core.ErrCodeInternal},
+	}
+
+	for i, v := range tests {
+		if code := errorCode(v.err); code != v.code {
+			t.Fatalf("%d: expected %s got %s", i, v.code, code)
+		}
+	}
+}
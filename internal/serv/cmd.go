@@ -26,6 +26,7 @@ type ServConfig struct {
 	conf     *Config            // parsed config
 	confPath string             // path to config
 	db       *sql.DB            // database connection pool
+	apq      apqStore           // automatic persisted queries
 }
 
 type BuildInfo struct {
@@ -123,6 +124,20 @@ e.g. db:migrate -+1
 		Run:   cmdDBReset(servConf),
 	})
 
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "db:snapshot [FILE]",
+		Short: "Save the database schema to a snapshot file",
+		Long:  "Save the database schema to a snapshot file (defaults to 'schema_snapshot' in the config), GraphJin will start using the snapshot instead of querying the database for its schema",
+		Run:   cmdDBSnapshot(servConf),
+	})
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "schema:sdl [FILE]",
+		Short: "Print the GraphQL schema",
+		Long:  "Print the GraphQL schema in the schema definition language (SDL) or save it to a file",
+		Run:   cmdSchemaSDL(servConf),
+	})
+
 	rootCmd.AddCommand(&cobra.Command{
 		Use:   "new APP-NAME",
 		Short: "Create a new application",
@@ -0,0 +1,45 @@
+package serv
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/dosco/graphjin/core"
+	"github.com/spf13/cobra"
+)
+
+func cmdSchemaSDL(servConf *ServConfig) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		var err error
+
+		if servConf.conf, err = initConf(servConf); err != nil {
+			servConf.log.Fatalf("Failed to read config: %s", err)
+		}
+
+		// the database is not needed when the schema is loaded from a snapshot
+		if servConf.conf.SchemaSnapshot == "" {
+			servConf.db, err = initDB(servConf, true, false)
+			if err != nil {
+				servConf.log.Fatalf("Failed to connect to database: %s", err)
+			}
+		}
+
+		servConf.conf.DisableAllowList = true
+
+		gj, err = core.NewGraphJin(&servConf.conf.Core, servConf.db)
+		if err != nil {
+			servConf.log.Fatalf("GraphJin failed to initialize: %s", err)
+		}
+
+		sdl := gj.SchemaSDL()
+
+		if len(args) == 0 {
+			fmt.Print(sdl)
+			return
+		}
+
+		if err := ioutil.WriteFile(args[0], []byte(sdl), 0644); err != nil {
+			servConf.log.Fatalf("Failed to write schema: %s", err)
+		}
+	}
+}
@@ -32,6 +32,8 @@ func cmdServ(servConf *ServConfig) func(*cobra.Command, []string) {
 			fatalInProd(servConf, err, "failed to initialize")
 		}
 
+		initSchemaWatcher(servConf)
+
 		startHTTP(servConf)
 	}
 }
@@ -0,0 +1,54 @@
+package serv
+
+import (
+	"os"
+
+	"github.com/dosco/graphjin/core"
+	"github.com/spf13/cobra"
+)
+
+func cmdDBSnapshot(servConf *ServConfig) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		var err error
+
+		if servConf.conf, err = initConf(servConf); err != nil {
+			servConf.log.Fatalf("Failed to read config: %s", err)
+		}
+
+		sfile := servConf.conf.SchemaSnapshot
+
+		if len(args) != 0 {
+			sfile = args[0]
+		}
+
+		if sfile == "" {
+			servConf.log.Fatal("Snapshot file not specified, set 'schema_snapshot' in the config")
+		}
+
+		servConf.db, err = initDB(servConf, true, false)
+		if err != nil {
+			servConf.log.Fatalf("Failed to connect to database: %s", err)
+		}
+
+		// discover the schema from the database and not the old snapshot
+		servConf.conf.SchemaSnapshot = ""
+		servConf.conf.DisableAllowList = true
+
+		gj, err = core.NewGraphJin(&servConf.conf.Core, servConf.db)
+		if err != nil {
+			servConf.log.Fatalf("GraphJin failed to initialize: %s", err)
+		}
+
+		f, err := os.Create(sfile)
+		if err != nil {
+			servConf.log.Fatalf("Failed to create snapshot file: %s", err)
+		}
+		defer f.Close()
+
+		if err := gj.WriteSchemaSnapshot(f); err != nil {
+			servConf.log.Fatalf("Failed to write snapshot: %s", err)
+		}
+
+		servConf.log.Infof("Schema snapshot saved: %s", sfile)
+	}
+}
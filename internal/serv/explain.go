@@ -0,0 +1,53 @@
+package serv
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/dosco/graphjin/internal/serv/internal/auth"
+)
+
+// apiV1ExplainHandler returns the SQL and the query plan for a query, it's
+// only available in development mode
+func apiV1ExplainHandler(sc *ServConfig) http.Handler {
+	h, err := auth.WithAuth(http.HandlerFunc(sc.apiV1Explain), &sc.conf.Auth)
+	if err != nil {
+		sc.log.Fatalf("Error initializing auth: %s", err)
+	}
+	return h
+}
+
+func (sc *ServConfig) apiV1Explain(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	b, err := ioutil.ReadAll(io.LimitReader(r.Body, maxReadBytes))
+	if err != nil {
+		renderErr(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	req := gqlReq{}
+
+	if err := json.Unmarshal(b, &req); err != nil {
+		renderErr(w, err)
+		return
+	}
+
+	ex, err := gj.ExplainEx(r.Context(), req.Query, req.OpName, req.Vars)
+	if err != nil {
+		renderErr(w, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(ex); err != nil {
+		renderErr(w, err)
+	}
+}
@@ -1,6 +1,7 @@
 package serv
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -19,7 +20,9 @@ import (
 )
 
 const (
-	maxReadBytes = 100000 // 100Kb
+	maxReadBytes  = 100000 // 100Kb
+	maxBatchSize  = 10
+	tracingHeader = "X-Apollo-Tracing"
 )
 
 var (
@@ -30,13 +33,16 @@ type gqlReq struct {
 	OpName string          `json:"operationName"`
 	Query  string          `json:"query"`
 	Vars   json.RawMessage `json:"variables"`
+	Ext    extensions      `json:"extensions"`
 }
 
 type errorResp struct {
-	Error string `json:"error"`
+	Errors []core.Error `json:"errors"`
 }
 
 func apiV1Handler(sc *ServConfig) http.Handler {
+	sc.apq = newAPQStore(sc)
+
 	h, err := auth.WithAuth(http.HandlerFunc(sc.apiV1()), &sc.conf.Auth)
 	if err != nil {
 		sc.log.Fatalf("Error initializing auth: %s", err)
@@ -70,22 +76,14 @@ func (sc *ServConfig) apiV1() func(http.ResponseWriter, *http.Request) {
 			return
 		}
 
-		b, err := ioutil.ReadAll(io.LimitReader(r.Body, maxReadBytes))
-		if err != nil {
-			renderErr(w, err)
-			return
-		}
-		defer r.Body.Close()
-
-		req := gqlReq{}
+		rc := core.ReqConfig{Vars: make(map[string]interface{}), Header: r.Header}
 
-		if err = json.Unmarshal(b, &req); err != nil {
-			renderErr(w, err)
-			return
+		// tracing can be enabled per request using a header
+		// except in production
+		if !sc.conf.Production && r.Header.Get(tracingHeader) != "" {
+			rc.EnableTracing = true
 		}
 
-		rc := core.ReqConfig{Vars: make(map[string]interface{})}
-
 		for k, v := range sc.conf.HeaderVars {
 			rc.Vars[k] = func() string {
 				if v1, ok := r.Header[v]; ok {
@@ -95,19 +93,59 @@ func (sc *ServConfig) apiV1() func(http.ResponseWriter, *http.Request) {
 			}
 		}
 
-		res, err := gj.GraphQL(ct, req.Query, req.Vars, &rc)
+		req := gqlReq{}
+
+		if r.Method == http.MethodGet {
+			if err := parseGetReq(r, &req); err != nil {
+				renderErr(w, err)
+				return
+			}
+		} else {
+			b, err := ioutil.ReadAll(io.LimitReader(r.Body, maxReadBytes))
+			if err != nil {
+				renderErr(w, err)
+				return
+			}
+			defer r.Body.Close()
 
-		if err == nil {
-			if sc.conf.CacheControl != "" && res.Operation() == core.OpQuery {
-				w.Header().Set("Cache-Control", sc.conf.CacheControl)
+			// A JSON array is a batch of requests
+			if b = bytes.TrimSpace(b); len(b) != 0 && b[0] == '[' {
+				sc.apiV1Batch(w, r, b, &rc)
+				return
 			}
 
-			//nolint: errcheck
-			err = json.NewEncoder(w).Encode(res)
+			if err := json.Unmarshal(b, &req); err != nil {
+				renderErr(w, err)
+				return
+			}
 		}
 
-		if err != nil {
+		if err := sc.resolvePersistedQuery(&req); err != nil {
 			renderErr(w, err)
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			if op, _ := core.OperationEx(req.Query, req.OpName); op == core.OpMutation {
+				renderErr(w, errGetMutation)
+				return
+			}
+		}
+
+		res, err := gj.GraphQLEx(ct, req.Query, req.OpName, req.Vars, &rc)
+
+		if err == nil && len(res.Errors) == 0 &&
+			sc.conf.CacheControl != "" && res.Operation() == core.OpQuery {
+			w.Header().Set("Cache-Control", sc.conf.CacheControl)
+		}
+
+		// errors are returned as part of the result
+		if err1 := json.NewEncoder(w).Encode(res); err1 != nil {
+			renderErr(w, err1)
+
+			if err == nil {
+				err = err1
+			}
 		}
 
 		if sc.conf.telemetryEnabled() {
@@ -132,6 +170,92 @@ func (sc *ServConfig) apiV1() func(http.ResponseWriter, *http.Request) {
 	}
 }
 
+func (sc *ServConfig) apiV1Batch(w http.ResponseWriter, r *http.Request, b []byte, rc *core.ReqConfig) {
+	ct := r.Context()
+
+	var reqs []gqlReq
+
+	if err := json.Unmarshal(b, &reqs); err != nil {
+		renderErr(w, err)
+		return
+	}
+
+	max := sc.conf.MaxBatchSize
+	if max == 0 {
+		max = maxBatchSize
+	}
+
+	if len(reqs) > max {
+		renderErr(w, fmt.Errorf("batch size exceeds the limit of %d requests", max))
+		return
+	}
+
+	res := make([]*core.Result, len(reqs))
+	breqs := make([]core.BatchReq, 0, len(reqs))
+	idx := make([]int, 0, len(reqs))
+
+	// a persisted query that cannot be resolved only
+	// fails its own entry in the batch
+	for i, req := range reqs {
+		if err := sc.resolvePersistedQuery(&req); err != nil {
+			res[i] = &core.Result{Errors: []core.Error{{
+				Message:    err.Error(),
+				Extensions: core.ErrorExtensions{Code: errorCode(err)},
+			}}}
+			continue
+		}
+		breqs = append(breqs, core.BatchReq{Query: req.Query, OpName: req.OpName, Vars: req.Vars})
+		idx = append(idx, i)
+	}
+
+	if len(breqs) != 0 {
+		for i, v := range gj.GraphQLBatch(ct, breqs, rc) {
+			res[idx[i]] = v
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		renderErr(w, err)
+	}
+
+	if sc.conf.telemetryEnabled() {
+		span := trace.FromContext(ct)
+		span.AddAttributes(trace.Int64Attribute("batch_size", int64(len(res))))
+		ochttp.SetRoute(ct, apiRoute)
+	}
+
+	if sc.logLevel >= LogLevelInfo {
+		for _, v := range res {
+			var err error
+			if len(v.Errors) != 0 {
+				err = errors.New(v.Errors[0].Message)
+			}
+			sc.reqLog(v, err)
+		}
+	}
+}
+
+// parseGetReq reads a GraphQL request from the URL parameters
+// of a GET request
+func parseGetReq(r *http.Request, req *gqlReq) error {
+	q := r.URL.Query()
+
+	req.Query = q.Get("query")
+	req.OpName = q.Get("operationName")
+
+	if v := q.Get("variables"); v != "" {
+		req.Vars = json.RawMessage(v)
+	}
+
+	if v := q.Get("extensions"); v != "" {
+		if err := json.Unmarshal([]byte(v), &req.Ext); err != nil {
+			return fmt.Errorf("invalid extensions: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func (sc *ServConfig) reqLog(res *core.Result, err error) {
 	fields := []zapcore.Field{
 		zap.String("op", res.OperationName()),
@@ -157,8 +281,27 @@ func renderErr(w http.ResponseWriter, err error) {
 		w.WriteHeader(http.StatusUnauthorized)
 	}
 
-	err1 := json.NewEncoder(w).Encode(errorResp{err.Error()})
+	res := errorResp{Errors: []core.Error{{
+		Message:    err.Error(),
+		Extensions: core.ErrorExtensions{Code: errorCode(err)},
+	}}}
+
+	err1 := json.NewEncoder(w).Encode(res)
 	if err1 != nil {
 		panic(fmt.Errorf("%s: %w", err, err1))
 	}
 }
+
+// errorCode returns the code set in the extensions of an error
+// returned by the service, errors from GraphJin use its codes
+func errorCode(err error) string {
+	switch err {
+	case errUnauthorized:
+		return core.ErrCodeUnauthorized
+	case errPersistedQueryNotFound:
+		return errCodePersistedQueryNotFound
+	case errPersistedQueryMismatch, errGetMutation:
+		return core.ErrCodeValidation
+	}
+	return core.ErrorCode(err)
+}
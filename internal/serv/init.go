@@ -104,6 +104,10 @@ func initConf(servConfig *ServConfig) (*Config, error) {
 		c.AllowListFile = c.relPath("./allow.list")
 	}
 
+	if c.SchemaSnapshot != "" {
+		c.SchemaSnapshot = c.relPath(c.SchemaSnapshot)
+	}
+
 	if c.Production {
 		c.EnforceAllowList = true
 	}
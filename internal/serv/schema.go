@@ -0,0 +1,142 @@
+package serv
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dosco/graphjin/internal/serv/internal/auth"
+	"github.com/jackc/pgx/v4/stdlib"
+)
+
+const (
+	// schemaChannel is the Postgres channel notified on schema changes
+	schemaChannel = "graphjin_schema"
+
+	// schemaReloadDelay is the time to wait for more schema changes
+	// (eg. the rest of a migration) before reloading
+	schemaReloadDelay = 1 * time.Second
+
+	// schemaListenRetry is the time to wait before listening again
+	// after the connection is lost
+	schemaListenRetry = 5 * time.Second
+)
+
+// apiV1ReloadHandler reloads the database schema, it's only
+// available in development mode
+func apiV1ReloadHandler(sc *ServConfig) http.Handler {
+	h, err := auth.WithAuth(http.HandlerFunc(sc.apiV1Reload), &sc.conf.Auth)
+	if err != nil {
+		sc.log.Fatalf("Error initializing auth: %s", err)
+	}
+	return h
+}
+
+func (sc *ServConfig) apiV1Reload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := gj.Reload(); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		renderErr(w, err)
+		return
+	}
+
+	sc.log.Info("Database schema reloaded")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// initSchemaWatcher reloads the database schema when a notification is received
+// on the graphjin_schema channel. On Postgres an event trigger can be used to
+// send a notification when tables are created or altered.
+func initSchemaWatcher(sc *ServConfig) {
+	if !sc.conf.WatchSchema {
+		return
+	}
+
+	if sc.conf.DBType == "mysql" {
+		sc.log.Warn("reload_on_schema_change is not supported on MySQL")
+		return
+	}
+
+	ch := make(chan struct{}, 1)
+
+	go sc.schemaReloader(ch)
+
+	go func() {
+		for {
+			if err := sc.listenSchema(ch); err != nil {
+				sc.log.Warnf("Schema watcher: %s", err)
+			}
+			time.Sleep(schemaListenRetry)
+		}
+	}()
+}
+
+// listenSchema listens on a dedicated connection since the
+// connections from the pool are shared
+func (sc *ServConfig) listenSchema(ch chan<- struct{}) error {
+	dc, err := initPostgres(sc, true, false)
+	if err != nil {
+		return err
+	}
+
+	db, err := sql.Open(dc.driverName, dc.connString)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	c := context.Background()
+
+	conn, err := db.Conn(c)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		dc, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.New("unsupported database driver")
+		}
+		pc := dc.Conn()
+
+		if _, err := pc.Exec(c, "LISTEN "+schemaChannel); err != nil {
+			return err
+		}
+
+		for {
+			if _, err := pc.WaitForNotification(c); err != nil {
+				return err
+			}
+
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	})
+}
+
+// schemaReloader reloads the schema once for a burst of notifications
+func (sc *ServConfig) schemaReloader(ch chan struct{}) {
+	for range ch {
+		time.Sleep(schemaReloadDelay)
+
+		select {
+		case <-ch:
+		default:
+		}
+
+		if err := gj.Reload(); err != nil {
+			sc.log.Errorf("Failed to reload database schema: %s", err)
+			continue
+		}
+		sc.log.Info("Database schema reloaded")
+	}
+}
@@ -140,6 +140,13 @@ func routeHandler(sc *ServConfig) (http.Handler, error) {
 		apiRoute:  apiHandler,
 	}
 
+	// returns the SQL and query plan for a query and
+	// reloads the database schema
+	if !sc.conf.Production {
+		routes[path.Join(path.Dir(apiRoute), "explain")] = apiV1ExplainHandler(sc)
+		routes[path.Join(path.Dir(apiRoute), "reload")] = apiV1ReloadHandler(sc)
+	}
+
 	if err := setActionRoutes(sc, routes); err != nil {
 		return nil, err
 	}
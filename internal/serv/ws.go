@@ -25,7 +25,7 @@ type gqlWsResp struct {
 	Type    string `json:"type"`
 	Payload struct {
 		Data   json.RawMessage `json:"data"`
-		Errors []string        `json:"errors,omitempty"`
+		Errors []core.Error    `json:"errors,omitempty"`
 	} `json:"payload"`
 }
 
@@ -131,7 +131,7 @@ func (sc *ServConfig) apiV1Ws(w http.ResponseWriter, r *http.Request) {
 			if run {
 				continue
 			}
-			m, err = gj.Subscribe(ctx, msg.Payload.Query, msg.Payload.Vars, nil)
+			m, err = gj.SubscribeEx(ctx, msg.Payload.Query, msg.Payload.OpName, msg.Payload.Vars, nil)
 			if err == nil {
 				go sc.waitForData(done, conn, m, msg)
 				run = true
@@ -181,10 +181,7 @@ func (sc *ServConfig) waitForData(done chan bool, conn *ws.Conn, m *core.Member,
 		case v := <-m.Result:
 			res := gqlWsResp{ID: req.ID, Type: ptype}
 			res.Payload.Data = v.Data
-
-			if v.Error != "" {
-				res.Payload.Errors = []string{v.Error}
-			}
+			res.Payload.Errors = v.Errors
 
 			if err = enc.Encode(res); err != nil {
 				continue